@@ -0,0 +1,191 @@
+package belenios
+
+import (
+	"archive/tar"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"strings"
+)
+
+// Election archive
+//
+// Tar file of entries named by the SHA256 hex of their content
+//
+//	<hash>.data   json data
+//	<hash>.event  json ArchiveEvent
+//
+// Events are chained by parent hash, in this order
+//
+//	Setup, Ballot..., EndBallots, EncryptedTally, Shuffle..., EndShuffles,
+//	PartialDecryption..., Result
+var archiveEvents = map[string]struct {
+	phase  int
+	single bool // at most one event
+	data   bool // with payload
+}{
+	"Setup":             {0, true, true},
+	"Ballot":            {1, false, true},
+	"EndBallots":        {2, true, false},
+	"EncryptedTally":    {3, true, true},
+	"Shuffle":           {4, false, true},
+	"EndShuffles":       {5, true, false},
+	"PartialDecryption": {6, false, true},
+	"Result":            {7, true, true},
+}
+
+// Open and read election archive file
+func OpenArchive(file string) (Archive, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return Archive{}, err
+	}
+	defer f.Close()
+	return ReadArchive(f)
+}
+
+// Read archive entries, verify the events chain and rebuild the election
+func ReadArchive(r io.Reader) (Archive, error) {
+	var (
+		a      Archive
+		events []ArchiveEvent
+		hashes []string
+	)
+	data := make(map[string][]byte)
+
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return a, fmt.Errorf(" Archive\n  %s\n", err)
+		}
+		content, err := ioutil.ReadAll(tr)
+		if err != nil {
+			return a, fmt.Errorf(" Archive\n  %s\n", err)
+		}
+
+		// Content addressed entries
+		name := path.Base(hdr.Name)
+		ext := path.Ext(name)
+		hash := strings.TrimSuffix(name, ext)
+		sum := sha256.Sum256(content)
+		if hex.EncodeToString(sum[:]) != hash {
+			return a, fmt.Errorf(" Archive entry %s\n  content hash %s\n  KO !!!!!\n", name, hex.EncodeToString(sum[:]))
+		}
+		switch ext {
+		case ".data":
+			data[hash] = content
+		case ".event":
+			var e ArchiveEvent
+			if err := json.Unmarshal(content, &e); err != nil {
+				return a, fmt.Errorf(" Archive event %s\n  %s\n", name, err)
+			}
+			events = append(events, e)
+			hashes = append(hashes, hash)
+		default:
+			return a, fmt.Errorf(" Archive entry %s\n  unknown entry\n", name)
+		}
+	}
+
+	// Hash chain
+	if len(events) == 0 {
+		return a, fmt.Errorf(" Archive without event\n")
+	}
+	for i, e := range events {
+		if (i == 0 && e.Parent != nil) || (i > 0 && (e.Parent == nil || *e.Parent != hashes[i-1])) {
+			return a, fmt.Errorf(" Archive event %d %s\n  not chained to previous event\n", i+1, e.Type)
+		}
+		if e.Height != i {
+			return a, fmt.Errorf(" Archive event %d %s\n  height %d\n", i+1, e.Type, e.Height)
+		}
+	}
+	a.Events = len(events)
+	a.LastEvent = hashes[len(hashes)-1]
+
+	// Rebuild election from events
+	phase := -1
+	for i, e := range events {
+		kind, ok := archiveEvents[e.Type]
+		if !ok {
+			return a, fmt.Errorf(" Archive event %d\n  unknown type %s\n", i+1, e.Type)
+		}
+		if kind.phase < phase || (kind.single && kind.phase == phase) || (i == 0) != (e.Type == "Setup") {
+			return a, fmt.Errorf(" Archive event %d %s\n  out of order\n", i+1, e.Type)
+		}
+		phase = kind.phase
+
+		var payload []byte
+		if kind.data {
+			if e.Payload == nil || data[*e.Payload] == nil {
+				return a, fmt.Errorf(" Archive event %d %s\n  missing payload\n", i+1, e.Type)
+			}
+			payload = data[*e.Payload]
+		}
+
+		var err error
+		switch e.Type {
+		case "Setup":
+			err = a.readSetup(payload, data)
+		case "Ballot":
+			var b Ballot
+			b, err = ReadBallot(payload)
+			a.Ballots = append(a.Ballots, b)
+		case "EncryptedTally":
+			var t ArchiveTally
+			if err = json.Unmarshal(payload, &t); err == nil {
+				a.Result.NumTallied = t.NumTallied
+				a.Result.TotalWeight = t.TotalWeight
+				err = json.Unmarshal(data[t.EncryptedTally], &a.Result.EncryptedTally)
+			}
+		case "Shuffle":
+			a.Shuffles++ // proofs not verified
+		case "PartialDecryption":
+			var pd PartialDecryption
+			err = json.Unmarshal(payload, &pd)
+			a.Result.PartialDecryptions = append(a.Result.PartialDecryptions, pd)
+		case "Result":
+			var res Result
+			err = json.Unmarshal(payload, &res)
+			a.Result.Result = res.Result
+			a.Result.NonHomomorphicResult = res.NonHomomorphicResult
+		}
+		if err != nil {
+			return a, fmt.Errorf(" Archive event %d %s\n  %s\n", i+1, e.Type, err)
+		}
+	}
+	return a, nil
+}
+
+// Election, trustees and credentials of Setup event
+func (a *Archive) readSetup(payload []byte, data map[string][]byte) error {
+	var setup ArchiveSetup
+	if err := json.Unmarshal(payload, &setup); err != nil {
+		return err
+	}
+	if data[setup.Election] == nil || data[setup.Trustees] == nil || data[setup.Credentials] == nil {
+		return fmt.Errorf("missing setup data")
+	}
+	elec, err := ReadElection(data[setup.Election])
+	if err != nil {
+		return fmt.Errorf("election: %s", err)
+	}
+	a.Election = elec
+	trustees, err := ReadTrustees(data[setup.Trustees])
+	if err != nil {
+		return fmt.Errorf("trustees: %s", err)
+	}
+	a.Trustees = trustees
+	a.Credentials, a.Weights, err = parseCredentials("public_creds.json", data[setup.Credentials])
+	if err != nil {
+		return fmt.Errorf("credentials: %s", err)
+	}
+	return nil
+}
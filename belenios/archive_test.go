@@ -0,0 +1,151 @@
+package belenios
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Archive writer for tests
+type testArchive struct {
+	buf    bytes.Buffer
+	tw     *tar.Writer
+	last   *string
+	height int
+}
+
+func newTestArchive() *testArchive {
+	ta := &testArchive{}
+	ta.tw = tar.NewWriter(&ta.buf)
+	return ta
+}
+
+func (ta *testArchive) entry(name string, content []byte) {
+	ta.tw.WriteHeader(&tar.Header{Name: name, Mode: 0644, Size: int64(len(content))})
+	ta.tw.Write(content)
+}
+
+func (ta *testArchive) data(content []byte) string {
+	sum := sha256.Sum256(content)
+	hash := hex.EncodeToString(sum[:])
+	ta.entry(hash+".data", content)
+	return hash
+}
+
+func (ta *testArchive) json(v interface{}) string {
+	j, _ := json.Marshal(v)
+	return ta.data(j)
+}
+
+func (ta *testArchive) event(typ string, payload string) {
+	e := ArchiveEvent{Parent: ta.last, Height: ta.height, Type: typ}
+	if payload != "" {
+		e.Payload = &payload
+	}
+	j, _ := json.Marshal(e)
+	sum := sha256.Sum256(j)
+	hash := hex.EncodeToString(sum[:])
+	ta.entry(hash+".event", j)
+	ta.last = &hash
+	ta.height++
+}
+
+func (ta *testArchive) bytes() []byte {
+	ta.tw.Close()
+	return ta.buf.Bytes()
+}
+
+// Setup event from dataTest files
+func (ta *testArchive) setup(t *testing.T) {
+	election, _ := ioutil.ReadFile("../dataTest/election.json")
+	trustees, _ := ioutil.ReadFile("../dataTest/trustees.json")
+	creds, _ := ioutil.ReadFile("../dataTest/public_creds.txt")
+	ta.event("Setup", ta.json(ArchiveSetup{
+		Election:    ta.data(election),
+		Trustees:    ta.data(trustees),
+		Credentials: ta.json(strings.Fields(string(creds))),
+	}))
+}
+
+func TestArchive(t *testing.T) {
+	elec, res, ballots, trustees := readTestData(t)
+
+	ta := newTestArchive()
+	ta.setup(t)
+	f, _ := os.Open("../dataTest/ballots.jsons")
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		ta.event("Ballot", ta.data(append([]byte{}, scanner.Bytes()...)))
+	}
+	f.Close()
+	ta.event("EndBallots", "")
+	ta.event("EncryptedTally", ta.json(ArchiveTally{NumTallied: res.NumTallied, EncryptedTally: ta.json(res.EncryptedTally)}))
+	for i, pd := range res.PartialDecryptions {
+		pd.Owner = i + 1
+		ta.event("PartialDecryption", ta.json(pd))
+	}
+	ta.event("Result", ta.json(map[string]interface{}{"result": res.Result}))
+	archive := ta.bytes()
+
+	a, err := ReadArchive(bytes.NewReader(archive))
+	assert.Equal(t, nil, err, "ReadArchive")
+	assert.Equal(t, 4+len(ballots)+len(res.PartialDecryptions), a.Events, "Events")
+	assert.Equal(t, *ta.last, a.LastEvent, "Last event")
+	assert.Equal(t, elec, a.Election, "Election")
+	assert.Equal(t, ballots, a.Ballots, "Ballots")
+	assert.Equal(t, trustees, a.Trustees, "Trustees")
+	assert.Equal(t, 5, len(a.Credentials), "Credentials")
+	assert.Equal(t, res.NumTallied, a.Result.NumTallied, "num_tallied")
+	assert.Equal(t, res.EncryptedTally, a.Result.EncryptedTally, "Encrypted tally")
+	assert.Equal(t, res.Result, a.Result.Result, "Result")
+	assert.Equal(t, 1, a.Result.PartialDecryptions[0].Owner, "Owned partial decryption")
+
+	// Existing verifiers on rebuilt election
+	HJSON := Fingerprint(a.Election)
+	for _, b := range a.Ballots {
+		assert.Equal(t, nil, verifyBallot(b, a.Election, HJSON), "verifyBallot")
+	}
+	assert.Equal(t, nil, verifyDecryptionFactors(a.Election, a.Result, a.Trustees), "verifyDecryptionFactors")
+	results, err := DecryptResults(a.Election, a.Result, Count(a.Election, a.Ballots, a.Weights), a.Trustees, a.Result.NumTallied)
+	assert.Equal(t, nil, err, "DecryptResults")
+	assert.Equal(t, res.Result, results, "Decrypted results")
+
+	// Entry not matching its hash
+	ta = newTestArchive()
+	ta.entry("0000.data", []byte("{}"))
+	_, err = ReadArchive(bytes.NewReader(ta.bytes()))
+	assert.Contains(t, err.Error(), "Archive entry 0000.data\n  content hash", "Hash")
+
+	// Broken chain
+	ta = newTestArchive()
+	ta.setup(t)
+	other := "0000"
+	ta.last = &other
+	ta.event("EndBallots", "")
+	_, err = ReadArchive(bytes.NewReader(ta.bytes()))
+	assert.Contains(t, err.Error(), "Archive event 2 EndBallots\n  not chained", "Chain")
+
+	// Ballot after end of ballots
+	ta = newTestArchive()
+	ta.setup(t)
+	ta.event("EndBallots", "")
+	ta.event("Ballot", ta.json(ballots[0]))
+	_, err = ReadArchive(bytes.NewReader(ta.bytes()))
+	assert.Contains(t, err.Error(), "Archive event 3 Ballot\n  out of order", "Order")
+
+	// Missing payload
+	ta = newTestArchive()
+	ta.setup(t)
+	ta.event("Ballot", "")
+	_, err = ReadArchive(bytes.NewReader(ta.bytes()))
+	assert.Contains(t, err.Error(), "Archive event 2 Ballot\n  missing payload", "Payload")
+}
@@ -0,0 +1,214 @@
+package belenios
+
+import (
+	"fmt"
+	"math/big"
+)
+
+type Choice struct {
+	Alpha Element
+	Beta  Element
+}
+
+// Count ballots with encrypted results,
+// ciphertexts are raised to the credential weight
+func Count(elec Election, ballots []Ballot, weights map[string]int) [][]Choice {
+
+	grp := electionGroup(elec)
+
+	// Array for new count
+	var newCount [][]Choice
+
+	// Init array
+	for _, q := range elec.Questions {
+		var choices []Choice
+		if q.Type != QuestionHomomorphic { // no homomorphic count
+			newCount = append(newCount, choices)
+			continue
+		}
+		// start with Blank
+		if q.Blank {
+			choices = append(choices, Choice{Alpha: grp.One(), Beta: grp.One()})
+		}
+		for range q.Answers {
+			choices = append(choices, Choice{Alpha: grp.One(), Beta: grp.One()})
+		}
+		newCount = append(newCount, choices)
+	}
+
+	// New homomorphic count
+	for _, b := range ballots {
+		w := big.NewInt(int64(ballotWeight(b, weights)))
+		// Ballots homomorphic count
+		for ai, a := range b.Answers {
+			for ci, c := range a.Choices {
+				// Homomorphic Sum
+				a1, _ := grp.Parse(c.Alpha)
+				b1, _ := grp.Parse(c.Beta)
+				if w.Cmp(big.NewInt(1)) != 0 {
+					a1, b1 = grp.Exp(a1, w), grp.Exp(b1, w)
+				}
+				newCount[ai][ci].Alpha = grp.Mul(newCount[ai][ci].Alpha, a1)
+				newCount[ai][ci].Beta = grp.Mul(newCount[ai][ci].Beta, b1)
+
+			}
+		}
+	}
+
+	return newCount
+}
+
+// Verify num_tallied is the number of counted ballots
+func verifyNumTallied(res Result, counted []Ballot, invalid []string, duplicates []Duplicate) error {
+	diff := res.NumTallied - len(counted)
+	if diff == 0 {
+		return nil // no error
+	}
+	msg := fmt.Sprintf(" num_tallied %d for %d counted ballots (difference %+d)\n", res.NumTallied, len(counted), diff)
+	for _, tracker := range invalid {
+		msg += fmt.Sprintf("  invalid, not counted: %s\n", tracker)
+	}
+	for _, d := range duplicates {
+		msg += fmt.Sprintf("  not counted: %s\n", d.Tracker)
+	}
+	return fmt.Errorf("%s", msg)
+}
+
+// Largest result of a choice: num_tallied, published total_weight
+// or weight of counted ballots
+func resultBound(res Result, weight int) int {
+	max := res.NumTallied
+	if res.TotalWeight > max {
+		max = res.TotalWeight
+	}
+	if weight > max {
+		max = weight
+	}
+	return max
+}
+
+// Decrypt with partial decryption factors, results from 0 to max
+func DecryptResults(elec Election, res Result, newCount [][]Choice, trustees []Trustee, max int) ([][]int, error) {
+
+	grp := electionGroup(elec)
+	g := grp.G()
+
+	// [4.18]  Election result
+	// Discret log for max values
+	DL := make(map[string]int)
+	dlt := grp.One()
+	for i := 0; i <= max; i++ {
+		DL[dlt.String()] = i
+		dlt = grp.Mul(dlt, g)
+	}
+
+	// Array for new results
+	var newResults [][]int
+
+	// Init array
+	for _, q := range elec.Questions {
+		var choices []int
+		if q.Type != QuestionHomomorphic { // nil as in result.json
+			newResults = append(newResults, choices)
+			continue
+		}
+		// start with Blank
+		if q.Blank {
+			choices = append(choices, 0)
+		}
+		for range q.Answers {
+			choices = append(choices, 0)
+		}
+		newResults = append(newResults, choices)
+	}
+
+	// Combined decryption factors, verified by verifyDecryptionFactors
+	factors, err := combineFactors(elec, res, trustees)
+	if err != nil {
+		return newResults, err
+	}
+
+	for i, _ := range newCount {
+		if elec.Questions[i].Type != QuestionHomomorphic {
+			continue // decrypted ballots from mixnet, not a count
+		}
+		if elec.Questions[i].Blank {
+			readAlpha, _ := grp.Parse(res.EncryptedTally[i][0].Alpha)
+			readBeta, _ := grp.Parse(res.EncryptedTally[i][0].Beta)
+			alpha := newCount[i][0].Alpha
+			beta := newCount[i][0].Beta
+			if readAlpha == nil || readBeta == nil || !grp.Equal(alpha, readAlpha) || !grp.Equal(beta, readBeta) {
+				return newResults, fmt.Errorf("Read blank Alpha and Beta != Computed Alpha and Beta")
+			}
+			// [4.18]  Election result
+			// result = logg(beta/f)
+			F := factors[i][0]
+			t := grp.Mul(beta, grp.Inv(F))
+			newResults[i][0] = DL[t.String()]
+		}
+
+		a := elec.Questions[i].Answers
+		bpos := 0
+		if elec.Questions[i].Blank {
+			bpos = 1
+		}
+		for ci, _ := range a {
+			readAlpha, _ := grp.Parse(res.EncryptedTally[i][ci+bpos].Alpha)
+			readBeta, _ := grp.Parse(res.EncryptedTally[i][ci+bpos].Beta)
+			alpha := newCount[i][ci+bpos].Alpha
+			beta := newCount[i][ci+bpos].Beta
+			if readAlpha == nil || readBeta == nil || !grp.Equal(alpha, readAlpha) || !grp.Equal(beta, readBeta) {
+				return newResults, fmt.Errorf("Read Alpha and Beta != Computed Alpha and Beta")
+			}
+			// [4.18]  Election result
+			// result = logg(beta/f)
+			F := factors[i][ci+bpos]
+			t := grp.Mul(beta, grp.Inv(F))
+			newResults[i][ci+bpos] = DL[t.String()]
+		}
+	}
+
+	return newResults, nil // no error
+}
+
+// Result of a question: count of each answer in Rows, or decrypted
+// ballots of a non-homomorphic question, Bounds is "" for min = max = 1
+type ResultTable struct {
+	Question string
+	Bounds   string
+	Rows     []ResultRow
+	Ballots  [][]int
+}
+
+type ResultRow struct {
+	Answer string
+	Count  int
+}
+
+// Result tables of decrypted questions, in election order
+func ResultTables(elec Election, res Result, results [][]int) []ResultTable {
+	var tables []ResultTable
+	for i, q := range elec.Questions {
+		t := ResultTable{Question: q.Question}
+		switch {
+		case q.Type == QuestionHomomorphic && i < len(results):
+			if q.Max != 1 {
+				t.Bounds = fmt.Sprintf("min %d, max %d", q.Min, q.Max)
+			}
+			bpos := 0
+			if q.Blank {
+				t.Rows = append(t.Rows, ResultRow{Answer: "Blank", Count: results[i][0]})
+				bpos = 1
+			}
+			for ci, a := range q.Answers {
+				t.Rows = append(t.Rows, ResultRow{Answer: a, Count: results[i][ci+bpos]})
+			}
+		case q.Type == QuestionNonHomomorphic && i < len(res.NonHomomorphicResult):
+			t.Ballots = res.NonHomomorphicResult[i]
+		default:
+			continue // not decrypted
+		}
+		tables = append(tables, t)
+	}
+	return tables
+}
@@ -0,0 +1,396 @@
+package belenios
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+)
+
+// Counting methods for decrypted non-homomorphic ballots,
+// one value by answer in each ballot:
+//
+//	schulze, stv: rank of the answer, 1 is preferred, 0 unranked
+//	mj: grade of the answer, 1 is best, grades of question extra if any
+//
+// A ballot with only 0 values is blank.
+var CountingMethods = []string{"schulze", "mj", "stv"}
+
+func isBlank(b []int) bool {
+	for _, v := range b {
+		if v != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// Condorcet-Schulze
+type SchulzeCount struct {
+	Blank    int
+	Raw      [][]int // Raw[i][j] ballots preferring i to j
+	Strength [][]int // strongest paths
+	Winners  [][]int // ranked groups of tied answers
+}
+
+func schulze(nAnswers int, ballots [][]int) (SchulzeCount, error) {
+	var s SchulzeCount
+	s.Raw = make([][]int, nAnswers)
+	s.Strength = make([][]int, nAnswers)
+	for i := range s.Raw {
+		s.Raw[i] = make([]int, nAnswers)
+		s.Strength[i] = make([]int, nAnswers)
+	}
+
+	for ib, b := range ballots {
+		if len(b) != nAnswers {
+			return s, fmt.Errorf(" Ballot %d with %d values for %d answers\n", ib+1, len(b), nAnswers)
+		}
+		if isBlank(b) {
+			s.Blank++
+			continue
+		}
+		for i := range b {
+			for j := range b {
+				// ranked before, unranked is last
+				if i != j && b[i] > 0 && (b[j] == 0 || b[i] < b[j]) {
+					s.Raw[i][j]++
+				}
+			}
+		}
+	}
+
+	// Strongest paths, Floyd-Warshall
+	for i := range s.Raw {
+		for j := range s.Raw {
+			if i != j && s.Raw[i][j] > s.Raw[j][i] {
+				s.Strength[i][j] = s.Raw[i][j]
+			}
+		}
+	}
+	for i := range s.Raw {
+		for j := range s.Raw {
+			if i == j {
+				continue
+			}
+			for k := range s.Raw {
+				if k == i || k == j {
+					continue
+				}
+				m := s.Strength[j][i]
+				if s.Strength[i][k] < m {
+					m = s.Strength[i][k]
+				}
+				if m > s.Strength[j][k] {
+					s.Strength[j][k] = m
+				}
+			}
+		}
+	}
+
+	// Ranking: not beaten by any remaining answer
+	remaining := make(map[int]bool)
+	for i := 0; i < nAnswers; i++ {
+		remaining[i] = true
+	}
+	for len(remaining) > 0 {
+		var group []int
+		for i := 0; i < nAnswers; i++ {
+			if !remaining[i] {
+				continue
+			}
+			beaten := false
+			for j := range remaining {
+				if s.Strength[j][i] > s.Strength[i][j] {
+					beaten = true
+				}
+			}
+			if !beaten {
+				group = append(group, i)
+			}
+		}
+		for _, i := range group {
+			delete(remaining, i)
+		}
+		s.Winners = append(s.Winners, group)
+	}
+	return s, nil
+}
+
+// Majority Judgment
+type MJCount struct {
+	Blank   int
+	Invalid int
+	Grades  [][]int // Grades[i][g] number of grade g+1 for answer i
+	Medians [][]int // successive median grades, for tie breaking
+	Winners [][]int // ranked groups of tied answers
+}
+
+// Majority Judgment with nGrades grades, or up to the highest grade given
+// when nGrades is 0
+func majorityJudgment(nAnswers, nGrades int, ballots [][]int) (MJCount, error) {
+	var m MJCount
+	maxGrade := nGrades
+	var valid [][]int
+	for ib, b := range ballots {
+		if len(b) != nAnswers {
+			return m, fmt.Errorf(" Ballot %d with %d values for %d answers\n", ib+1, len(b), nAnswers)
+		}
+		if isBlank(b) {
+			m.Blank++
+			continue
+		}
+		ok := true
+		for _, v := range b {
+			if v <= 0 || (maxGrade > 0 && v > maxGrade) {
+				ok = false
+			}
+			if maxGrade == 0 && v > nGrades {
+				nGrades = v
+			}
+		}
+		if !ok {
+			m.Invalid++
+			continue
+		}
+		valid = append(valid, b)
+	}
+
+	for i := 0; i < nAnswers; i++ {
+		grades := make([]int, nGrades)
+		var all []int
+		for _, b := range valid {
+			grades[b[i]-1]++
+			all = append(all, b[i])
+		}
+		m.Grades = append(m.Grades, grades)
+
+		// lower median, removed until no grade left
+		sort.Ints(all)
+		var medians []int
+		for len(all) > 0 {
+			k := len(all) / 2
+			medians = append(medians, all[k])
+			all = append(all[:k], all[k+1:]...)
+		}
+		m.Medians = append(m.Medians, medians)
+	}
+
+	order := make([]int, nAnswers)
+	for i := range order {
+		order[i] = i
+	}
+	cmp := func(a, b int) int { // <0: a before b
+		for k := range m.Medians[a] {
+			if d := m.Medians[a][k] - m.Medians[b][k]; d != 0 {
+				return d
+			}
+		}
+		return 0
+	}
+	sort.SliceStable(order, func(x, y int) bool { return cmp(order[x], order[y]) < 0 })
+	for k, i := range order {
+		if k > 0 && cmp(order[k-1], i) == 0 {
+			m.Winners[len(m.Winners)-1] = append(m.Winners[len(m.Winners)-1], i)
+		} else {
+			m.Winners = append(m.Winners, []int{i})
+		}
+	}
+	return m, nil
+}
+
+// Single Transferable Vote
+//
+// Droop quota, surplus is the last ballots of an elected answer,
+// ties eliminate the last answer
+type STVRound struct {
+	Votes     []int // by answer, -1 when elected or eliminated
+	Answer    int
+	Elected   bool
+	Exhausted int // ballots without remaining preference
+}
+
+type STVCount struct {
+	Invalid int
+	Quota   int
+	Rounds  []STVRound
+	Winners []int
+}
+
+// Preferences from ranks 1..k, 0 unranked, nil if invalid
+func stvPreferences(b []int) []int {
+	n := 0
+	for _, v := range b {
+		if v < 0 || v > len(b) {
+			return nil
+		}
+		if v > 0 {
+			n++
+		}
+	}
+	prefs := make([]int, n)
+	for i, v := range b {
+		if v == 0 {
+			continue
+		}
+		if v > n || prefs[v-1] != 0 {
+			return nil
+		}
+		prefs[v-1] = i + 1
+	}
+	for k := range prefs {
+		prefs[k]--
+	}
+	return prefs
+}
+
+func stv(nAnswers, seats int, ballots [][]int) (STVCount, error) {
+	var s STVCount
+	var valid [][]int
+	for ib, b := range ballots {
+		if len(b) != nAnswers {
+			return s, fmt.Errorf(" Ballot %d with %d values for %d answers\n", ib+1, len(b), nAnswers)
+		}
+		prefs := stvPreferences(b)
+		if len(prefs) == 0 {
+			s.Invalid++
+			continue
+		}
+		valid = append(valid, prefs)
+	}
+	s.Quota = len(valid)/(seats+1) + 1
+
+	hopeful := make([]bool, nAnswers)
+	for i := range hopeful {
+		hopeful[i] = true
+	}
+	piles := make([][][]int, nAnswers)
+	exhausted := 0
+	assign := func(prefs []int) {
+		for _, a := range prefs {
+			if hopeful[a] {
+				piles[a] = append(piles[a], prefs)
+				return
+			}
+		}
+		exhausted++
+	}
+	for _, prefs := range valid {
+		assign(prefs)
+	}
+
+	for len(s.Winners) < seats {
+		var r STVRound
+		nHopeful := 0
+		best, worst := -1, -1
+		for i := 0; i < nAnswers; i++ {
+			r.Votes = append(r.Votes, -1)
+			if !hopeful[i] {
+				continue
+			}
+			nHopeful++
+			r.Votes[i] = len(piles[i])
+			if best < 0 || r.Votes[i] > r.Votes[best] {
+				best = i
+			}
+			if worst < 0 || r.Votes[i] <= r.Votes[worst] {
+				worst = i
+			}
+		}
+		if nHopeful == 0 {
+			break
+		}
+		r.Exhausted = exhausted
+
+		if r.Votes[best] >= s.Quota || nHopeful <= seats-len(s.Winners) {
+			r.Answer, r.Elected = best, true
+			s.Winners = append(s.Winners, best)
+			hopeful[best] = false
+			if len(piles[best]) > s.Quota {
+				surplus := piles[best][s.Quota:]
+				piles[best] = piles[best][:s.Quota]
+				for _, prefs := range surplus {
+					assign(prefs)
+				}
+			}
+		} else {
+			r.Answer = worst
+			hopeful[worst] = false
+			transfer := piles[worst]
+			piles[worst] = nil
+			for _, prefs := range transfer {
+				assign(prefs)
+			}
+		}
+		s.Rounds = append(s.Rounds, r)
+	}
+	return s, nil
+}
+
+// Counting of a non-homomorphic question, Question is its index
+// in the election, only the count of Method is set
+type Counting struct {
+	Question int
+	Method   string
+	Seats    int
+	Schulze  *SchulzeCount
+	MJ       *MJCount
+	STV      *STVCount
+}
+
+// Number of grades of question extra {"grades":["Excellent",...]},
+// 0 without grades
+func questionGrades(q Question) (int, error) {
+	if q.Extra == nil {
+		return 0, nil
+	}
+	var extra struct {
+		Grades []string `json:"grades"`
+	}
+	if err := json.Unmarshal(q.Extra, &extra); err != nil {
+		return 0, fmt.Errorf("extra: %s", err)
+	}
+	return len(extra.Grades), nil
+}
+
+// Count decrypted ballots of non-homomorphic questions with method,
+// intermediate tables included
+func CountNonHomomorphic(method string, seats int, elec Election, res Result) ([]Counting, error) {
+	var countings []Counting
+	for i, q := range elec.Questions {
+		if q.Type != QuestionNonHomomorphic || i >= len(res.NonHomomorphicResult) {
+			continue
+		}
+		ballots := res.NonHomomorphicResult[i]
+		n := len(q.Answers)
+		c := Counting{Question: i, Method: method, Seats: seats}
+
+		switch method {
+		case "schulze":
+			s, err := schulze(n, ballots)
+			if err != nil {
+				return countings, err
+			}
+			c.Schulze = &s
+		case "mj":
+			grades, err := questionGrades(q)
+			if err != nil {
+				return countings, fmt.Errorf(" Question %d grades\n  %s\n", i+1, err)
+			}
+			m, err := majorityJudgment(n, grades, ballots)
+			if err != nil {
+				return countings, err
+			}
+			c.MJ = &m
+		case "stv":
+			s, err := stv(n, seats, ballots)
+			if err != nil {
+				return countings, err
+			}
+			c.STV = &s
+		default:
+			return countings, fmt.Errorf(" Unknown counting method %s\n", method)
+		}
+		countings = append(countings, c)
+	}
+	return countings, nil // no error
+}
@@ -0,0 +1,137 @@
+package belenios
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func repeat(n int, b []int) [][]int {
+	var ballots [][]int
+	for i := 0; i < n; i++ {
+		ballots = append(ballots, b)
+	}
+	return ballots
+}
+
+func TestSchulze(t *testing.T) {
+	// Schulze method example with 45 voters, ranks of A, B, C, D, E
+	var ballots [][]int
+	ballots = append(ballots, repeat(5, []int{1, 3, 2, 5, 4})...) // ACBED
+	ballots = append(ballots, repeat(5, []int{1, 5, 4, 2, 3})...) // ADECB
+	ballots = append(ballots, repeat(8, []int{4, 1, 5, 3, 2})...) // BEDAC
+	ballots = append(ballots, repeat(3, []int{2, 3, 1, 5, 4})...) // CABED
+	ballots = append(ballots, repeat(7, []int{2, 4, 1, 5, 3})...) // CAEBD
+	ballots = append(ballots, repeat(2, []int{3, 2, 1, 4, 5})...) // CBADE
+	ballots = append(ballots, repeat(7, []int{5, 4, 2, 1, 3})...) // DCEBA
+	ballots = append(ballots, repeat(8, []int{3, 2, 5, 4, 1})...) // EBADC
+	ballots = append(ballots, []int{0, 0, 0, 0, 0})
+
+	s, err := schulze(5, ballots)
+	assert.Equal(t, nil, err, "schulze")
+	assert.Equal(t, 1, s.Blank, "Blank ballot")
+	assert.Equal(t, []int{0, 20, 26, 30, 22}, s.Raw[0], "Raw preferences of A")
+	assert.Equal(t, []int{0, 28, 28, 30, 24}, s.Strength[0], "Strongest paths of A")
+	assert.Equal(t, [][]int{{4}, {0}, {2}, {1}, {3}}, s.Winners, "E > A > C > B > D")
+
+	// Tie
+	s, _ = schulze(2, [][]int{{1, 2}, {2, 1}})
+	assert.Equal(t, [][]int{{0, 1}}, s.Winners, "Tie")
+
+	_, err = schulze(3, [][]int{{1, 2}})
+	assert.Contains(t, err.Error(), "Ballot 1 with 2 values for 3 answers", "Bad ballot")
+}
+
+func TestMajorityJudgment(t *testing.T) {
+	// grades 1 (best) to 4
+	ballots := [][]int{
+		{1, 2, 4},
+		{2, 2, 3},
+		{3, 1, 1},
+		{2, 3, 4},
+		{0, 0, 0},
+		{1, 0, 2},
+	}
+	m, err := majorityJudgment(3, 0, ballots)
+	assert.Equal(t, nil, err, "majorityJudgment")
+	assert.Equal(t, 1, m.Blank, "Blank ballot")
+	assert.Equal(t, 1, m.Invalid, "Invalid ballot")
+	assert.Equal(t, []int{1, 2, 1, 0}, m.Grades[0], "Grades of A")
+	// A: 1,2,2,3 B: 1,2,2,3 -> same medians, C: 1,3,4,4
+	assert.Equal(t, []int{2, 2, 3, 1}, m.Medians[0], "Medians of A")
+	assert.Equal(t, [][]int{{0, 1}, {2}}, m.Winners, "A = B > C")
+
+	m, _ = majorityJudgment(2, 0, [][]int{{1, 2}, {2, 2}, {3, 1}})
+	assert.Equal(t, [][]int{{1}, {0}}, m.Winners, "B > A")
+
+	// 5 grades of the question, lowest not given, above is invalid
+	m, _ = majorityJudgment(3, 5, append(ballots, []int{6, 1, 1}))
+	assert.Equal(t, []int{1, 2, 1, 0, 0}, m.Grades[0], "Grades of A with 5 grades")
+	assert.Equal(t, 2, m.Invalid, "Grade above 5")
+}
+
+func TestSTV(t *testing.T) {
+	// ranks of A, B, C, D
+	var ballots [][]int
+	ballots = append(ballots, repeat(4, []int{1, 2, 0, 0})...) // A B
+	ballots = append(ballots, repeat(2, []int{0, 1, 0, 0})...) // B
+	ballots = append(ballots, repeat(3, []int{0, 0, 1, 2})...) // C D
+	ballots = append(ballots, repeat(1, []int{0, 2, 0, 1})...) // D B
+	ballots = append(ballots, []int{1, 1, 0, 0}, []int{2, 0, 0, 0})
+
+	s, err := stv(4, 2, ballots)
+	assert.Equal(t, nil, err, "stv")
+	assert.Equal(t, 2, s.Invalid, "Invalid ballots")
+	assert.Equal(t, 4, s.Quota, "Droop quota for 10 ballots, 2 seats")
+	assert.Equal(t, []int{0, 1}, s.Winners, "A and B elected")
+
+	// A 4 elected, D 1 eliminated to B, B and C tie: C eliminated, B last
+	assert.Equal(t, STVRound{Votes: []int{4, 2, 3, 1}, Answer: 0, Elected: true}, s.Rounds[0], "Round 1")
+	assert.Equal(t, STVRound{Votes: []int{-1, 2, 3, 1}, Answer: 3}, s.Rounds[1], "Round 2")
+	assert.Equal(t, STVRound{Votes: []int{-1, 3, 3, -1}, Answer: 2}, s.Rounds[2], "Round 3")
+	assert.Equal(t, STVRound{Votes: []int{-1, 3, -1, -1}, Answer: 1, Elected: true, Exhausted: 3}, s.Rounds[3], "Round 4")
+
+	// Surplus of A transferred to B
+	s, _ = stv(3, 2, append(repeat(5, []int{1, 2, 0}), repeat(3, []int{0, 0, 1})...))
+	assert.Equal(t, 3, s.Quota, "Droop quota for 8 ballots, 2 seats")
+	assert.Equal(t, []int{0, 2}, s.Winners, "A and C elected")
+	assert.Equal(t, []int{-1, 2, 3}, s.Rounds[1].Votes, "Surplus")
+
+	assert.Equal(t, []int{1, 2}, stvPreferences([]int{0, 1, 2}), "Preferences")
+	assert.Equal(t, []int(nil), stvPreferences([]int{1, 3, 0}), "Gap in ranks")
+}
+
+func TestCountNonHomomorphic(t *testing.T) {
+	elec := Election{Questions: []Question{
+		{Type: QuestionHomomorphic, Answers: []string{"X", "Y"}, Min: 0, Max: 2, Question: "Approve"},
+		{Type: QuestionNonHomomorphic, Answers: []string{"A", "B"}, Question: "Rank"},
+	}}
+	res := Result{NonHomomorphicResult: [][][]int{nil, {{1, 2}, {1, 2}, {2, 1}}}}
+
+	countings, err := CountNonHomomorphic("schulze", 1, elec, res)
+	assert.Equal(t, nil, err, "CountNonHomomorphic")
+	assert.Equal(t, 1, len(countings), "Non-homomorphic questions")
+	assert.Equal(t, 1, countings[0].Question, "Question index")
+	assert.Equal(t, [][]int{{0}, {1}}, countings[0].Schulze.Winners, "Schulze A > B")
+	countings, _ = CountNonHomomorphic("mj", 1, elec, res)
+	assert.Equal(t, [][]int{{0}, {1}}, countings[0].MJ.Winners, "MJ A > B")
+	elec.Questions[1].Extra = json.RawMessage(`{"grades":["Good","Fair","Poor"]}`)
+	countings, _ = CountNonHomomorphic("mj", 1, elec, res)
+	assert.Equal(t, []int{2, 1, 0}, countings[0].MJ.Grades[0], "MJ grades of question")
+	elec.Questions[1].Extra = json.RawMessage(`{"grades":3}`)
+	_, err = CountNonHomomorphic("mj", 1, elec, res)
+	assert.Contains(t, err.Error(), "Question 2 grades", "Bad grades")
+	elec.Questions[1].Extra = nil
+	countings, _ = CountNonHomomorphic("stv", 1, elec, res)
+	assert.Equal(t, []int{0}, countings[0].STV.Winners, "STV A")
+	_, err = CountNonHomomorphic("borda", 1, elec, res)
+	assert.Contains(t, err.Error(), "Unknown counting method borda", "Unknown method")
+
+	tables := ResultTables(elec, res, [][]int{{2, 1}, nil})
+	assert.Equal(t, []ResultTable{
+		{Question: "Approve", Bounds: "min 0, max 2", Rows: []ResultRow{{"X", 2}, {"Y", 1}}},
+		{Question: "Rank", Ballots: [][]int{{1, 2}, {1, 2}, {2, 1}}},
+	}, tables, "Result tables")
+	assert.Equal(t, 1, len(ResultTables(elec, res, nil)), "Homomorphic results not decrypted")
+}
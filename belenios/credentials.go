@@ -0,0 +1,155 @@
+package belenios
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Public credentials files, newer first
+var CredentialFiles = []string{"public_creds.json", "public_creds.txt"}
+
+// Read public credentials and their weights, default weight is 1
+//
+//	public_creds.txt: one "credential" or "credential,weight" by line
+//	public_creds.json: json list of "credential" or "credential,weight"
+func parseCredentials(file string, byteValue []byte) ([]string, map[string]int, error) {
+	var lines []string
+	if strings.HasSuffix(file, ".json") {
+		if err := json.Unmarshal(byteValue, &lines); err != nil {
+			return nil, nil, err
+		}
+	} else {
+		scanner := bufio.NewScanner(bytes.NewReader(byteValue))
+		for scanner.Scan() {
+			lines = append(lines, scanner.Text())
+		}
+	}
+
+	var creds []string
+	weights := make(map[string]int)
+	for _, l := range lines {
+		l = strings.TrimSpace(l)
+		if l == "" {
+			continue
+		}
+		fields := strings.SplitN(l, ",", 2)
+		creds = append(creds, fields[0])
+		if len(fields) == 2 {
+			w, err := strconv.Atoi(strings.TrimSpace(fields[1]))
+			if err != nil || w < 1 {
+				return nil, nil, fmt.Errorf("bad weight %q for credential %s", fields[1], fields[0])
+			}
+			weights[fields[0]] = w
+		}
+	}
+	return creds, weights, nil
+}
+
+// Read public credentials from first existing file in dir,
+// return file name, "" when no file
+func ReadCredentials(dir string) (string, []string, map[string]int, error) {
+	for _, file := range CredentialFiles {
+		byteValue, err := ioutil.ReadFile(dir + "/" + file)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return file, nil, nil, err
+		}
+		creds, weights, err := parseCredentials(file, byteValue)
+		if err != nil {
+			return file, nil, nil, fmt.Errorf("%s: %s", file, err)
+		}
+		return file, creds, weights, nil
+	}
+	return "", nil, nil, nil
+}
+
+// Weight of a ballot credential, 1 when not weighted
+func ballotWeight(b Ballot, weights map[string]int) int {
+	if w, ok := weights[b.Signature.PublicKey]; ok {
+		return w
+	}
+	return 1
+}
+
+// Total weight of ballots
+func TotalWeight(ballots []Ballot, weights map[string]int) int {
+	total := 0
+	for _, b := range ballots {
+		total += ballotWeight(b, weights)
+	}
+	return total
+}
+
+// Verify total_weight, when published, is the weight of counted ballots
+func verifyTotalWeight(res Result, total int) error {
+	if res.TotalWeight != 0 && res.TotalWeight != total {
+		return fmt.Errorf(" total_weight %d for %d weight of counted ballots\n", res.TotalWeight, total)
+	}
+	return nil // no error
+}
+
+// Ballot signed with a public credential
+func verifyBallotCredential(b Ballot, creds map[string]bool) error {
+	if !creds[b.Signature.PublicKey] {
+		return fmt.Errorf(" Ballot signed with unknown credential\n  %s\n", b.Signature.PublicKey)
+	}
+	return nil // no error
+}
+
+// Number of used and unused credentials
+func credentialsUsage(ballots []Ballot, creds map[string]bool) (int, int) {
+	used := make(map[string]bool)
+	for _, b := range ballots {
+		if creds[b.Signature.PublicKey] {
+			used[b.Signature.PublicKey] = true
+		}
+	}
+	return len(used), len(creds) - len(used)
+}
+
+// Set of credentials
+func credentialsSet(creds []string) map[string]bool {
+	set := make(map[string]bool)
+	for _, c := range creds {
+		set[c] = true
+	}
+	return set
+}
+
+// Ballot replaced by a later ballot with the same credential
+type Duplicate struct {
+	Index      int    `json:"index"` // ballot number in ballots.jsons
+	Tracker    string `json:"tracker"`
+	ReplacedBy string `json:"replaced_by"`
+}
+
+// Apply revote rule: the last ballot of each credential counts,
+// index is the ballot number in ballots.jsons of each ballot
+func lastBallots(ballots []Ballot, index []int) ([]Ballot, []Duplicate) {
+	last := make(map[string]int)
+	for i, b := range ballots {
+		last[b.Signature.PublicKey] = i
+	}
+
+	var (
+		counted    []Ballot
+		duplicates []Duplicate
+	)
+	for i, b := range ballots {
+		l := last[b.Signature.PublicKey]
+		if l != i {
+			duplicates = append(duplicates, Duplicate{Index: index[i], Tracker: b.Tracker, ReplacedBy: ballots[l].Tracker})
+			continue
+		}
+		counted = append(counted, b)
+	}
+	return counted, duplicates
+}
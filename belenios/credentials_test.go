@@ -0,0 +1,113 @@
+package belenios
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCredentials(t *testing.T) {
+	_, _, ballots, _ := readTestData(t)
+
+	file, creds, weights, err := ReadCredentials("../dataTest")
+	assert.Equal(t, nil, err, "ReadCredentials")
+	assert.Equal(t, "public_creds.txt", file, "Credentials file")
+	assert.Equal(t, 5, len(creds), "5 credentials")
+	assert.Equal(t, 0, len(weights), "Not weighted")
+
+	set := credentialsSet(creds)
+	for _, b := range ballots {
+		assert.Equal(t, nil, verifyBallotCredential(b, set), "verifyBallotCredential")
+	}
+	used, unused := credentialsUsage(ballots, set)
+	assert.Equal(t, 3, used, "3 used credentials")
+	assert.Equal(t, 2, unused, "2 unused credentials")
+
+	b := ballots[0]
+	b.Signature.PublicKey = "2"
+	err = verifyBallotCredential(b, set)
+	assert.Contains(t, err.Error(), "unknown credential", "Unknown credential")
+
+	// json and weighted variants
+	creds, weights, err = parseCredentials("public_creds.json", []byte(`["123","456,2"]`))
+	assert.Equal(t, nil, err, "parseCredentials json")
+	assert.Equal(t, []string{"123", "456"}, creds, "json credentials")
+	assert.Equal(t, map[string]int{"456": 2}, weights, "json weights")
+	creds, weights, err = parseCredentials("public_creds.txt", []byte("123,3\n\n456\n"))
+	assert.Equal(t, nil, err, "parseCredentials txt")
+	assert.Equal(t, []string{"123", "456"}, creds, "txt credentials")
+	assert.Equal(t, map[string]int{"123": 3}, weights, "txt weights")
+	_, _, err = parseCredentials("public_creds.txt", []byte("123,0\n"))
+	assert.Contains(t, err.Error(), "bad weight", "Bad weight")
+
+	file, _, _, _ = ReadCredentials("../doc")
+	assert.Equal(t, "", file, "No credentials file")
+}
+
+func TestRevote(t *testing.T) {
+	_, res, ballots, _ := readTestData(t)
+
+	counted, duplicates := lastBallots(ballots, []int{1, 2, 3})
+	assert.Equal(t, ballots, counted, "No duplicate")
+	assert.Equal(t, 0, len(duplicates), "No duplicate")
+	assert.Equal(t, nil, verifyNumTallied(res, counted, nil, duplicates), "verifyNumTallied")
+
+	// Ballot 3 revote with ballot 1 credential
+	revote := append([]Ballot{}, ballots...)
+	revote[2].Signature.PublicKey = revote[0].Signature.PublicKey
+	counted, duplicates = lastBallots(revote, []int{1, 2, 3})
+	assert.Equal(t, []Ballot{revote[1], revote[2]}, counted, "Last ballot counts")
+	assert.Equal(t, []Duplicate{{Index: 1, Tracker: ballots[0].Tracker, ReplacedBy: ballots[2].Tracker}}, duplicates, "Duplicate")
+
+	err := verifyNumTallied(res, counted, nil, duplicates)
+	assert.Equal(t, " num_tallied 3 for 2 counted ballots (difference +1)\n  not counted: "+ballots[0].Tracker+"\n", err.Error(), "num_tallied with revote")
+
+	// Invalid ballot and revote
+	err = verifyNumTallied(res, counted[1:], []string{ballots[1].Tracker}, duplicates)
+	assert.Equal(t, " num_tallied 3 for 1 counted ballots (difference +2)\n  invalid, not counted: "+ballots[1].Tracker+"\n  not counted: "+ballots[0].Tracker+"\n", err.Error(), "num_tallied with invalid ballot")
+}
+
+func TestWeights(t *testing.T) {
+	elec, dataRes, ballots, _ := readTestData(t)
+
+	// Ballot 1 with weight 3 counts as 3 ballots
+	weights := map[string]int{ballots[0].Signature.PublicKey: 3}
+	assert.Equal(t, 3, ballotWeight(ballots[0], weights), "Weighted ballot")
+	assert.Equal(t, 1, ballotWeight(ballots[1], weights), "Default weight")
+	assert.Equal(t, 5, TotalWeight(ballots, weights), "Total weight")
+
+	weighted := Count(elec, ballots, weights)
+	repeated := Count(elec, append([]Ballot{ballots[0], ballots[0]}, ballots...), nil)
+	for i := range weighted {
+		for j := range weighted[i] {
+			assert.Equal(t, repeated[i][j].Alpha.String(), weighted[i][j].Alpha.String(), "Weighted alpha")
+			assert.Equal(t, repeated[i][j].Beta.String(), weighted[i][j].Beta.String(), "Weighted beta")
+		}
+	}
+
+	var res Result
+	res.TotalWeight = 4
+	assert.Contains(t, verifyTotalWeight(res, 5).Error(), "total_weight 4 for 5", "verifyTotalWeight")
+	res.TotalWeight = 0
+	assert.Equal(t, nil, verifyTotalWeight(res, 5), "No total_weight")
+
+	// Results above num_tallied need total weight for discrete log
+	tg := newTestGroup(elec)
+	x := tg.random()
+	trustees := []Trustee{{Kind: "Single", Single: &TrusteePublicKey{PublicKey: tg.Exp(tg.G(), x).String()}}}
+	var results [][]int
+	for _, q := range dataRes.Result {
+		var r []int
+		for _, v := range q {
+			r = append(r, 3*v)
+		}
+		results = append(results, r)
+	}
+	res = newTestTally(tg, tg.Exp(tg.G(), x), results, []int{1}, []*big.Int{x})
+	decrypted, _ := DecryptResults(elec, res, countFromTally(tg, res), trustees, res.NumTallied)
+	assert.NotEqual(t, results, decrypted, "Results above num_tallied")
+	decrypted, err := DecryptResults(elec, res, countFromTally(tg, res), trustees, resultBound(res, 9))
+	assert.Equal(t, nil, err, "DecryptResults weighted")
+	assert.Equal(t, results, decrypted, "Weighted results")
+}
@@ -0,0 +1,187 @@
+package belenios
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+)
+
+// Ed25519 twisted Edwards curve
+//
+//	-x**2 + y**2 = 1 + d x**2 y**2 (mod p), p = 2**255 - 19
+var (
+	edP, _ = new(big.Int).SetString("57896044618658097711785492504343953926634992332820282019728792003956564819949", 10)
+	edQ, _ = new(big.Int).SetString("7237005577332262213973186563042994240857116359379907606001950938285454250989", 10)
+	edD, _ = new(big.Int).SetString("37095705934669439343138083508754565189542113879843219016388785533085940283555", 10)
+	// sqrt(-1) = 2**((p-1)/4)
+	edI, _ = new(big.Int).SetString("19681161376707505956807079304988542015446066515923890162744021073123829784752", 10)
+	// base point
+	edG = &edPoint{
+		x: fromDecimal("15112221349535400772501151409588531511454012693041857206046113283949847762202"),
+		y: fromDecimal("46316835694926478169428394003475163141307993866256225615783033603165251855960"),
+	}
+)
+
+func fromDecimal(s string) *big.Int {
+	x, _ := new(big.Int).SetString(s, 10)
+	return x
+}
+
+// Point in affine coordinates
+type edPoint struct {
+	x, y *big.Int
+}
+
+// Compressed point: 32 bytes little endian y with sign of x in last bit, hex encoded
+func (a *edPoint) String() string {
+	b := make([]byte, 32)
+	y := a.y.Bytes()
+	for i := range y {
+		b[i] = y[len(y)-1-i]
+	}
+	b[31] |= byte(a.x.Bit(0) << 7)
+	return hex.EncodeToString(b)
+}
+
+// Ed25519 group of order q
+type ed25519Group struct{}
+
+func (ed25519Group) Name() string { return "Ed25519" }
+
+func (ed25519Group) G() Element { return edG }
+
+func (ed25519Group) Q() *big.Int { return edQ }
+
+func (ed25519Group) One() Element { return &edPoint{x: big.NewInt(0), y: big.NewInt(1)} }
+
+// Decompress point
+//
+//	x**2 = (y**2 - 1) / (d y**2 + 1)
+func (ed25519Group) Parse(s string) (Element, error) {
+	b, err := hex.DecodeString(s)
+	if err != nil || len(b) != 32 {
+		return nil, fmt.Errorf("bad Ed25519 point %q", s)
+	}
+	sign := uint(b[31] >> 7)
+	le := make([]byte, 32)
+	for i := range b {
+		le[31-i] = b[i]
+	}
+	le[0] &= 0x7f
+	y := new(big.Int).SetBytes(le)
+	if y.Cmp(edP) >= 0 {
+		return nil, fmt.Errorf("bad Ed25519 point %q", s)
+	}
+
+	y2 := new(big.Int).Mul(y, y)
+	u := new(big.Int).Sub(y2, big.NewInt(1))
+	v := new(big.Int).Mul(edD, y2)
+	v = v.Add(v, big.NewInt(1))
+	x2 := u.Mul(u, new(big.Int).ModInverse(v.Mod(v, edP), edP))
+	x2 = x2.Mod(x2, edP)
+
+	// x = x2**((p+3)/8), times sqrt(-1) if needed
+	e := new(big.Int).Add(edP, big.NewInt(3))
+	x := new(big.Int).Exp(x2, e.Rsh(e, 3), edP)
+	if new(big.Int).Exp(x, big.NewInt(2), edP).Cmp(x2) != 0 {
+		x = x.Mul(x, edI).Mod(x, edP)
+	}
+	if new(big.Int).Exp(x, big.NewInt(2), edP).Cmp(x2) != 0 {
+		return nil, fmt.Errorf("Ed25519 point %q not on curve", s)
+	}
+	if x.Sign() == 0 && sign == 1 {
+		return nil, fmt.Errorf("bad Ed25519 point %q", s)
+	}
+	if x.Bit(0) != sign {
+		x = x.Sub(edP, x)
+	}
+	return &edPoint{x: x, y: y}, nil
+}
+
+// On curve, in subgroup of order q and not the neutral element
+func (grp ed25519Group) IsMember(a Element) bool {
+	p, ok := a.(*edPoint)
+	if !ok || p == nil || grp.Equal(p, grp.One()) {
+		return false
+	}
+	x2 := new(big.Int).Mul(p.x, p.x)
+	y2 := new(big.Int).Mul(p.y, p.y)
+	left := new(big.Int).Sub(y2, x2)
+	right := new(big.Int).Mul(edD, x2)
+	right = right.Mul(right, y2).Add(right, big.NewInt(1))
+	if left.Sub(left, right).Mod(left, edP).Sign() != 0 {
+		return false
+	}
+	return grp.Equal(grp.Exp(p, edQ), grp.One())
+}
+
+// Point addition
+//
+//	x3 = (x1 y2 + y1 x2) / (1 + d x1 x2 y1 y2)
+//	y3 = (y1 y2 + x1 x2) / (1 - d x1 x2 y1 y2)
+func (ed25519Group) Mul(a, b Element) Element {
+	p1, p2 := a.(*edPoint), b.(*edPoint)
+	t := new(big.Int).Mul(p1.x, p2.x)
+	t = t.Mul(t, p1.y).Mul(t, p2.y).Mul(t, edD).Mod(t, edP)
+
+	x := new(big.Int).Mul(p1.x, p2.y)
+	x = x.Add(x, new(big.Int).Mul(p1.y, p2.x))
+	dx := new(big.Int).Add(big.NewInt(1), t)
+	x = x.Mul(x, dx.ModInverse(dx.Mod(dx, edP), edP)).Mod(x, edP)
+
+	y := new(big.Int).Mul(p1.y, p2.y)
+	y = y.Add(y, new(big.Int).Mul(p1.x, p2.x))
+	dy := new(big.Int).Sub(big.NewInt(1), t)
+	y = y.Mul(y, dy.ModInverse(dy.Mod(dy, edP), edP)).Mod(y, edP)
+
+	return &edPoint{x: x, y: y}
+}
+
+// Scalar multiplication, double and add
+func (grp ed25519Group) Exp(a Element, n *big.Int) Element {
+	if n.Sign() < 0 {
+		return grp.Exp(grp.Inv(a), new(big.Int).Neg(n))
+	}
+	r := grp.One()
+	for i := n.BitLen() - 1; i >= 0; i-- {
+		r = grp.Mul(r, r)
+		if n.Bit(i) == 1 {
+			r = grp.Mul(r, a)
+		}
+	}
+	return r
+}
+
+func (ed25519Group) Inv(a Element) Element {
+	p := a.(*edPoint)
+	return &edPoint{x: new(big.Int).Mod(new(big.Int).Neg(p.x), edP), y: new(big.Int).Set(p.y)}
+}
+
+func (ed25519Group) Equal(a, b Element) bool {
+	p1, p2 := a.(*edPoint), b.(*edPoint)
+	return p1.x.Cmp(p2.x) == 0 && p1.y.Cmp(p2.y) == 0
+}
+
+// First hash of s|counter decoding to a point, times cofactor 8
+func (grp ed25519Group) HashToGroup(s string) Element {
+	for i := 0; ; i++ {
+		h := sha256.Sum256([]byte(fmt.Sprintf("%s|%d", s, i)))
+		p, err := grp.Parse(hex.EncodeToString(h[:]))
+		if err != nil {
+			continue
+		}
+		p = grp.Exp(p, big.NewInt(8))
+		if grp.IsMember(p) {
+			return p
+		}
+	}
+}
+
+// Integers encoding of Belenios Ed25519 group is not supported
+func (ed25519Group) OfInts(xs []int) (Element, error) {
+	return nil, fmt.Errorf("integers encoding not supported in Ed25519 group")
+}
+
+// Standard group
+func (ed25519Group) Verify() error { return nil }
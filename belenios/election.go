@@ -0,0 +1,120 @@
+package belenios
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"sort"
+)
+
+// Known fields of election.json objects, by path
+var electionFields = map[string][]string{
+	"":                  {"version", "description", "name", "group", "public_key", "questions", "uuid", "administrator", "credential_authority"},
+	"group":             {"g", "p", "q"},
+	"public_key":        {"group", "y"},
+	"public_key.group":  {"g", "p", "q"},
+	"questions[]":       {"answers", "blank", "min", "max", "question", "type", "value", "extra"},
+	"questions[].value": {"answers", "blank", "min", "max", "question"},
+}
+
+// Read legacy election, version 1 elections (group and public key at
+// top level, ballots signed with hash and proof) fail
+func (e *Election) UnmarshalJSON(data []byte) error {
+	type election Election // without UnmarshalJSON
+	var v struct {
+		Version int `json:"version"`
+	}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	if v.Version != 0 {
+		return fmt.Errorf("unsupported election version %d", v.Version)
+	}
+	return json.Unmarshal(data, (*election)(e))
+}
+
+// Read election.json with fingerprint of the raw bytes
+func ReadElection(raw []byte) (Election, error) {
+	var elec Election
+	if err := json.Unmarshal(raw, &elec); err != nil {
+		return elec, err
+	}
+	elec.Fingerprint = electionFingerprint(raw)
+	elec.Ignored = ignoredFields(raw)
+	return elec, nil
+}
+
+// [4.14] fingerprint of election
+//
+//	HJSON(J) = BASE64(SHA256(J))
+//
+// J is election.json as published, without trailing newline
+func electionFingerprint(raw []byte) string {
+	hashJ := sha256.Sum256(bytes.TrimSpace(raw))
+	return base64.RawStdEncoding.EncodeToString(hashJ[:])
+}
+
+// Fields of election.json not read into Election
+func ignoredFields(raw []byte) []string {
+	var v interface{}
+	if json.Unmarshal(raw, &v) != nil {
+		return nil
+	}
+	found := make(map[string]bool)
+	walkFields("", v, found)
+
+	var ignored []string
+	for f := range found {
+		ignored = append(ignored, f)
+	}
+	sort.Strings(ignored)
+	return ignored
+}
+
+func walkFields(path string, v interface{}, found map[string]bool) {
+	switch v := v.(type) {
+	case []interface{}:
+		for _, e := range v {
+			walkFields(path+"[]", e, found)
+		}
+	case map[string]interface{}:
+		known, ok := electionFields[path]
+		if !ok {
+			return // leaf value, as group name
+		}
+		for k, e := range v {
+			if !contains(known, k) {
+				found[fieldPath(path, k)] = true
+				continue
+			}
+			walkFields(fieldPath(path, k), e, found)
+		}
+	}
+}
+
+func fieldPath(path, k string) string {
+	if path == "" {
+		return k
+	}
+	return path + "." + k
+}
+
+func contains(list []string, s string) bool {
+	for _, e := range list {
+		if e == s {
+			return true
+		}
+	}
+	return false
+}
+
+// Fingerprint of election, from raw election.json when available
+func Fingerprint(elec Election) string {
+	if elec.Fingerprint != "" {
+		return elec.Fingerprint
+	}
+	J, _ := json.Marshal(elec) // election not read from json
+	return electionFingerprint(J)
+}
@@ -0,0 +1,96 @@
+package belenios
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestElectionFingerprint(t *testing.T) {
+	raw, _ := ioutil.ReadFile("../dataTest/election.json")
+	elec, err := ReadElection(raw)
+	assert.Equal(t, nil, err, "ReadElection")
+	assert.Equal(t, "e1Jmque3h7bkC6gz/6mrSlOL/88MHP6L6wNGuDsRbHE", elec.Fingerprint, "Fingerprint of raw bytes")
+	assert.Equal(t, []string(nil), elec.Ignored, "No unknown field")
+
+	// New fields are hashed, not lost
+	extra := bytes.Replace(raw, []byte(`"questions":[{`), []byte(`"questions":[{"hint":{"k":1},`), 1)
+	extra = bytes.Replace(extra, []byte(`{"description"`), []byte(`{"date":"2026-01-01","description"`), 1)
+	elec, err = ReadElection(extra)
+	assert.Equal(t, nil, err, "ReadElection with new fields")
+	assert.NotEqual(t, "e1Jmque3h7bkC6gz/6mrSlOL/88MHP6L6wNGuDsRbHE", elec.Fingerprint, "Fingerprint with new fields")
+	J, _ := json.Marshal(elec)
+	assert.NotEqual(t, electionFingerprint(J), elec.Fingerprint, "Fingerprint not from parsed election")
+	assert.Equal(t, []string{"date", "questions[].hint"}, elec.Ignored, "Unknown fields")
+
+	HJSON := Fingerprint(elec)
+	assert.Equal(t, elec.Fingerprint, HJSON, "describeElection fingerprint")
+	b := Ballot{ElectionUUID: elec.UUID, ElectionHash: "e1Jmque3h7bkC6gz/6mrSlOL/88MHP6L6wNGuDsRbHE"}
+	assert.NotEqual(t, nil, verifyResponseToElection(b, elec.UUID, HJSON), "Ballot from previous election.json")
+}
+
+func TestElectionVersion(t *testing.T) {
+	legacy, _, ballots, _ := readTestData(t)
+	HJSON := Fingerprint(legacy)
+	assert.Equal(t, QuestionHomomorphic, legacy.Questions[0].Type, "Legacy question type")
+
+	// Tagged questions of a legacy election
+	var v map[string]interface{}
+	raw, _ := ioutil.ReadFile("../dataTest/election.json")
+	json.Unmarshal(raw, &v)
+	var questions []interface{}
+	for _, q := range v["questions"].([]interface{}) {
+		questions = append(questions, map[string]interface{}{"type": "Homomorphic", "value": q})
+	}
+	v["questions"] = questions
+	raw, _ = json.Marshal(v)
+
+	elec, err := ReadElection(raw)
+	assert.Equal(t, nil, err, "ReadElection tagged questions")
+	assert.Equal(t, []string(nil), elec.Ignored, "No unknown field")
+	assert.Equal(t, legacy.Questions, elec.Questions, "Tagged questions")
+	for _, b := range ballots {
+		assert.Equal(t, nil, verifyBallot(b, elec, HJSON), "verifyBallot with tagged questions")
+	}
+
+	// Version 1 and unknown versions fail at load time
+	v["version"] = 1
+	v["group"] = "BELENIOS-2048"
+	v["public_key"] = legacy.PublicKey.Y
+	raw, _ = json.Marshal(v)
+	_, err = ReadElection(raw)
+	assert.Contains(t, err.Error(), "unsupported election version 1", "Version 1")
+	v["version"] = 2
+	raw, _ = json.Marshal(v)
+	_, err = ReadElection(raw)
+	assert.Contains(t, err.Error(), "unsupported election version 2", "Unknown version")
+
+	// Unsupported question types fail at load time
+	var q Question
+	err = json.Unmarshal([]byte(`{"type":"Lists","value":{"question":"Lists","answers":[["L1","A","B"],["L2","C"]]},"extra":{"seats":2}}`), &q)
+	assert.Equal(t, "question of type Lists not supported", err.Error(), "Lists question")
+	delete(v, "version")
+	delete(v, "group")
+	delete(v, "public_key")
+	v["questions"] = append(questions, map[string]interface{}{"type": "Lists", "value": map[string]interface{}{"question": "Lists", "answers": [][]string{{"L1", "A"}}}})
+	raw, _ = json.Marshal(v)
+	_, err = ReadElection(raw)
+	assert.Contains(t, err.Error(), "question of type Lists not supported", "Lists election")
+
+	// Ballot signature of newer versions is not misread
+	_, err = ReadBallot([]byte(`{"answers":[],"signature":{"hash":"h","proof":{"challenge":"1","response":"2"}}}`))
+	assert.Equal(t, errBallotFormat, err, "Ballot signature with hash and proof")
+	dir := t.TempDir()
+	for _, f := range Files {
+		data, _ := ioutil.ReadFile("../dataTest/" + f)
+		if f == "ballots.jsons" {
+			data = append(data, `{"answers":[],"signature":{"hash":"h","proof":{}}}`+"\n"...)
+		}
+		ioutil.WriteFile(dir+"/"+f, data, 0644)
+	}
+	_, _, _, _, err = ReadFiles(dir)
+	assert.Equal(t, "ballots.jsons: ballot 4: ballot signature with hash and proof not supported", err.Error(), "ReadFiles newer ballot")
+}
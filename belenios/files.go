@@ -0,0 +1,77 @@
+package belenios
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+)
+
+// Election files, ballots.jsons has one json ballot by line
+var Files = []string{"election.json", "result.json", "ballots.jsons", "trustees.json"}
+
+// Read election files from dir
+func ReadFiles(dir string) (Election, Result, []Ballot, []Trustee, error) {
+	var (
+		elec     Election
+		res      Result
+		ballots  []Ballot
+		trustees []Trustee
+	)
+	for _, file := range Files {
+		jsonFile, err := os.Open(dir + "/" + file)
+		if err != nil {
+			return elec, res, ballots, trustees, err
+		}
+		defer jsonFile.Close()
+
+		switch file {
+		case "election.json":
+			byteValue, _ := ioutil.ReadAll(jsonFile)
+			elec, err = ReadElection(byteValue)
+		case "result.json":
+			byteValue, _ := ioutil.ReadAll(jsonFile)
+			json.Unmarshal(byteValue, &res)
+		case "ballots.jsons":
+			scanner := bufio.NewScanner(jsonFile)
+			scanner.Buffer(make([]byte, 64*1024), 16*1024*1024) // large ballots
+			for scanner.Scan() {
+				b, berr := ReadBallot(scanner.Bytes()) // bad json fails verification
+				if errors.Is(berr, errBallotFormat) {
+					err = fmt.Errorf("ballot %d: %s", len(ballots)+1, berr)
+					break
+				}
+				ballots = append(ballots, b)
+			}
+		case "trustees.json":
+			byteValue, _ := ioutil.ReadAll(jsonFile)
+			trustees, err = ReadTrustees(byteValue)
+		}
+		if err != nil {
+			return elec, res, ballots, trustees, fmt.Errorf("%s: %s", file, err)
+		}
+	}
+	return elec, res, ballots, trustees, nil
+}
+
+// Ballot signature of newer Belenios versions
+//
+//	"signature":{"hash":"...","proof":{"challenge":"...","response":"..."}}
+var errBallotFormat = errors.New("ballot signature with hash and proof not supported")
+
+// Read json ballot with its tracker, ballots of newer Belenios versions
+// fail with errBallotFormat instead of being misread
+func ReadBallot(raw []byte) (Ballot, error) {
+	var b Ballot
+	var v struct {
+		Signature map[string]json.RawMessage `json:"signature"`
+	}
+	if json.Unmarshal(raw, &v) == nil && (v.Signature["hash"] != nil || v.Signature["proof"] != nil) {
+		return b, errBallotFormat
+	}
+	err := json.Unmarshal(raw, &b)
+	b.Tracker = ballotTracker(raw)
+	return b, err
+}
@@ -0,0 +1,268 @@
+package belenios
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"math/big"
+)
+
+type knownGroup struct {
+	Name string
+	ID   string // name in version 1 elections
+	G    string
+	P    string
+	Q    string
+}
+
+// Standard Belenios finite field groups
+var knownGroups = []knownGroup{
+	{
+		Name: "Belenios default 2048-bit group",
+		ID:   "BELENIOS-2048",
+		G: "2402352677501852209227687703532399932712287657378364916510075318787663274146353219320285676155269678" +
+			"7996946682987493890950838965734256019006010684771644917354741372831046104586813145117816467554005274" +
+			"0288984613986453266121505579709716201616827031288643245666383486363578210615491841998253431518974065" +
+			"8186868651151358576410138882215396016043228843603930989333662772848406593138406010231675095763777982" +
+			"6651036068224066350766977640253462537730851331734951942489677540525736590494924776314759915751987751" +
+			"7771148149092045660020547812705472823814097251863985833411570056835369555342378147558249189605029668" +
+			"0037745308460627",
+		P: "2069478569142254640101364365750500806492298929575110409710088478705737421924271740192223725449768433" +
+			"8129066633138078958404960054389636289796393038773905722803605973749427671376777618898589872735865049" +
+			"0811670993105358677809800307904916540637771737641986785272734744763418356000356983051931442845617019" +
+			"1100078673730733356412397173289791324047457883446826065232797464795113767265869358218004631792207366" +
+			"8860052627186363386088796882120769432366149491002923444346373222145884100586421050242120365433561201" +
+			"3204811188524087310770141516662001623131771693721892480785077118278423174980732765988288251691831031" +
+			"25680162072880719",
+		Q: "78571733251071885079927659812671450121821421258408794611510081919805623223441",
+	},
+	{
+		Name: "RFC 3526 2048-bit MODP group",
+		ID:   "RFC-3526-2048",
+		G:    "2",
+		P: "3231700607131100730033891392642382824881794124114023911284200975140074170663435422261968941736356934" +
+			"7117901737909704191754605873209195028853758986185622153212175412514901774520270235796078236248884246" +
+			"1894775876411059286460994117232454266225221932305409190376805242355191256797158701170010580558776510" +
+			"3886184728025797605490356973256152616708133936179954133647655916036831789672907317838458968063967190" +
+			"0977202194168647225871031411336429319536193471636533209717077448227988588565369208645296636077250268" +
+			"9555059283627511211740969729980684105543595848665832916421362182310789909994486524682624169720359118" +
+			"52507045361090559",
+		Q: "1615850303565550365016945696321191412440897062057011955642100487570037085331717711130984470868178467" +
+			"3558950868954852095877302936604597514426879493092811076606087706257450887260135117898039118124442123" +
+			"0947387938205529643230497058616227133112610966152704595188402621177595628398579350585005290279388255" +
+			"1943092364012898802745178486628076308354066968089977066823827958018415894836453658919229484031983595" +
+			"0488601097084323612935515705668214659768096735818266604858538724113994294282684604322648318038625134" +
+			"4777529641813755605870484864990342052771797924332916458210681091155394954997243262341312084860179559" +
+			"26253522680545279",
+	},
+}
+
+// Minimal size of group parameters
+const (
+	minPBits = 2048
+	minQBits = 256
+)
+
+// Group element, String() is used in proofs hashes
+type Element interface {
+	String() string
+}
+
+// Election group
+type Group interface {
+	Name() string
+	G() Element
+	Q() *big.Int
+	One() Element
+	Parse(s string) (Element, error)
+	IsMember(a Element) bool // in subgroup of order q
+	Mul(a, b Element) Element
+	Exp(a Element, n *big.Int) Element
+	Inv(a Element) Element
+	Equal(a, b Element) bool
+	HashToGroup(s string) Element     // element with unknown discrete log
+	OfInts(xs []int) (Element, error) // plaintext of small integers
+	Verify() error                    // group parameters
+}
+
+// Group from election public key
+func electionGroup(elec Election) Group {
+	if elec.PublicKey.Group.Name == "Ed25519" {
+		return ed25519Group{}
+	}
+	g, _ := new(big.Int).SetString(elec.PublicKey.Group.G, 10)
+	p, _ := new(big.Int).SetString(elec.PublicKey.Group.P, 10)
+	q, _ := new(big.Int).SetString(elec.PublicKey.Group.Q, 10)
+	return modpGroup{g: g, p: p, q: q}
+}
+
+// Read group parameters {"g":...,"p":...,"q":...} or group name,
+// "Ed25519" or a known finite field group ID
+func (gp *GroupParams) UnmarshalJSON(data []byte) error {
+	type params GroupParams
+	if len(data) > 0 && data[0] == '"' {
+		if err := json.Unmarshal(data, &gp.Name); err != nil {
+			return err
+		}
+		for _, k := range knownGroups {
+			if k.ID == gp.Name {
+				gp.G, gp.P, gp.Q = k.G, k.P, k.Q
+			}
+		}
+		return nil
+	}
+	return json.Unmarshal(data, (*params)(gp))
+}
+
+func (gp GroupParams) MarshalJSON() ([]byte, error) {
+	type params GroupParams
+	if gp.Name != "" {
+		return json.Marshal(gp.Name)
+	}
+	return json.Marshal(params(gp))
+}
+
+// SHA256(s) mod q
+func hashQ(s string, q *big.Int) *big.Int {
+	hashS := sha256.Sum256([]byte(s))
+	bHashS := new(big.Int).SetBytes(hashS[:])
+	return bHashS.Mod(bHashS, q)
+}
+
+// Integer of bits length from SHA256(s|0) || SHA256(s|1) || ...
+func expandHash(s string, bits int) *big.Int {
+	var b []byte
+	for i := 0; len(b)*8 < bits; i++ {
+		h := sha256.Sum256([]byte(fmt.Sprintf("%s|%d", s, i)))
+		b = append(b, h[:]...)
+	}
+	x := new(big.Int).SetBytes(b)
+	return x.Rsh(x, uint(len(b)*8-bits))
+}
+
+// Name of a standard group, "" for other groups
+func GroupName(elec Election) string {
+	for _, k := range knownGroups {
+		if elec.PublicKey.Group.G == k.G && elec.PublicKey.Group.P == k.P && elec.PublicKey.Group.Q == k.Q {
+			return k.Name
+		}
+	}
+	return elec.PublicKey.Group.Name
+}
+
+// Verify group parameters
+func VerifyGroup(elec Election) error {
+	if gp := elec.PublicKey.Group; gp.Name != "" && gp.Name != "Ed25519" && gp.P == "" {
+		return fmt.Errorf(" Unknown election group %s\n", elec.PublicKey.Group.Name)
+	}
+	return electionGroup(elec).Verify()
+}
+
+// Multiplicative group of integers modulo p, subgroup of order q
+type modpGroup struct {
+	g, p, q *big.Int
+}
+
+func (grp modpGroup) Name() string { return "modp" }
+
+func (grp modpGroup) G() Element { return grp.g }
+
+func (grp modpGroup) Q() *big.Int { return grp.q }
+
+func (grp modpGroup) One() Element { return big.NewInt(1) }
+
+func (grp modpGroup) Parse(s string) (Element, error) {
+	x, ok := new(big.Int).SetString(s, 10)
+	if !ok {
+		return nil, fmt.Errorf("bad integer %q", s)
+	}
+	return x, nil
+}
+
+// 1 < x < p and x**q = 1 (mod p)
+func (grp modpGroup) IsMember(a Element) bool {
+	x, ok := a.(*big.Int)
+	if !ok || x == nil || x.Cmp(big.NewInt(1)) <= 0 || x.Cmp(grp.p) >= 0 {
+		return false
+	}
+	return new(big.Int).Exp(x, grp.q, grp.p).Cmp(big.NewInt(1)) == 0
+}
+
+func (grp modpGroup) Mul(a, b Element) Element {
+	x := new(big.Int).Mul(a.(*big.Int), b.(*big.Int))
+	return x.Mod(x, grp.p)
+}
+
+func (grp modpGroup) Exp(a Element, n *big.Int) Element {
+	return new(big.Int).Exp(a.(*big.Int), n, grp.p)
+}
+
+func (grp modpGroup) Inv(a Element) Element {
+	return new(big.Int).ModInverse(a.(*big.Int), grp.p)
+}
+
+func (grp modpGroup) Equal(a, b Element) bool {
+	return a.(*big.Int).Cmp(b.(*big.Int)) == 0
+}
+
+// (hash of s mod p)**((p-1)/q), retry with counter for 1
+func (grp modpGroup) HashToGroup(s string) Element {
+	k := new(big.Int).Sub(grp.p, big.NewInt(1))
+	k = k.Div(k, grp.q)
+	for i := 0; ; i++ {
+		x := expandHash(fmt.Sprintf("%s|%d", s, i), grp.p.BitLen()+64)
+		h := x.Exp(x.Mod(x, grp.p), k, grp.p)
+		if grp.IsMember(h) {
+			return h
+		}
+	}
+}
+
+// Belenios G.of_ints: x = 1 + bytes of xs (first most significant),
+// x or p-x in the subgroup of quadratic residues, needs p = 2q+1
+func (grp modpGroup) OfInts(xs []int) (Element, error) {
+	p1 := new(big.Int).Sub(grp.p, big.NewInt(1))
+	if p1.Cmp(new(big.Int).Lsh(grp.q, 1)) != 0 {
+		return nil, fmt.Errorf("integers encoding needs a safe prime group (RFC-3526-2048)")
+	}
+	b := make([]byte, len(xs))
+	for i, x := range xs {
+		if x < 0 || x > 255 {
+			return nil, fmt.Errorf("value %d not in [0, 255]", x)
+		}
+		b[i] = byte(x)
+	}
+	x := new(big.Int).SetBytes(b)
+	x = x.Add(x, big.NewInt(1))
+	if x.Cmp(grp.q) > 0 {
+		return nil, fmt.Errorf("%d values too large for the group", len(xs))
+	}
+	if new(big.Int).Exp(x, grp.q, grp.p).Cmp(big.NewInt(1)) != 0 {
+		x = x.Sub(grp.p, x)
+	}
+	return x, nil
+}
+
+// p and q primes, q divides p-1, g of order q
+func (grp modpGroup) Verify() error {
+	if grp.g == nil || grp.p == nil || grp.q == nil {
+		return fmt.Errorf(" Bad election group parameters\n")
+	}
+	if grp.p.BitLen() < minPBits || grp.q.BitLen() < minQBits {
+		return fmt.Errorf(" Weak election group\n  p: %d bits, q: %d bits\n", grp.p.BitLen(), grp.q.BitLen())
+	}
+	if !grp.p.ProbablyPrime(20) {
+		return fmt.Errorf(" Election group p is not prime\n")
+	}
+	if !grp.q.ProbablyPrime(20) {
+		return fmt.Errorf(" Election group q is not prime\n")
+	}
+	p1 := new(big.Int).Sub(grp.p, big.NewInt(1))
+	if new(big.Int).Mod(p1, grp.q).Sign() != 0 {
+		return fmt.Errorf(" Election group q does not divide p-1\n")
+	}
+	if !grp.IsMember(grp.g) {
+		return fmt.Errorf(" Election group g is not of order q\n")
+	}
+	return nil // no error
+}
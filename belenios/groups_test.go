@@ -0,0 +1,164 @@
+package belenios
+
+import (
+	"crypto/ed25519"
+	"crypto/sha512"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGroup(t *testing.T) {
+	elec, _, _, _ := readTestData(t)
+
+	assert.Equal(t, nil, VerifyGroup(elec), "VerifyGroup")
+	assert.Equal(t, "Belenios default 2048-bit group", GroupName(elec), "Default group")
+
+	for _, k := range knownGroups {
+		e := elec
+		e.PublicKey.Group.G, e.PublicKey.Group.P, e.PublicKey.Group.Q = k.G, k.P, k.Q
+		assert.Equal(t, nil, VerifyGroup(e), "VerifyGroup "+k.Name)
+		assert.Equal(t, k.Name, GroupName(e), "GroupName "+k.Name)
+	}
+
+	// g of order 2
+	e := elec
+	p1 := new(big.Int).Sub(prime(elec), big.NewInt(1))
+	e.PublicKey.Group.G = p1.String()
+	assert.Contains(t, VerifyGroup(e).Error(), "g is not of order q", "Bad generator")
+	assert.Equal(t, "", GroupName(e), "Custom group")
+
+	// q not prime
+	e = elec
+	e.PublicKey.Group.Q = new(big.Int).Lsh(big.NewInt(1), 300).String()
+	assert.Contains(t, VerifyGroup(e).Error(), "q is not prime", "Bad q")
+
+	// small q
+	e = elec
+	e.PublicKey.Group.Q = "3"
+	assert.Contains(t, VerifyGroup(e).Error(), "Weak election group", "Weak group")
+}
+
+func TestEd25519(t *testing.T) {
+	grp := ed25519Group{}
+
+	assert.Equal(t, "5866666666666666666666666666666666666666666666666666666666666666", grp.G().String(), "Base point encoding")
+	g, err := grp.Parse(grp.G().String())
+	assert.Equal(t, nil, err, "Parse base point")
+	assert.True(t, grp.Equal(grp.G(), g), "Parse base point")
+	assert.True(t, grp.IsMember(g), "Base point in group")
+	assert.True(t, grp.Equal(grp.One(), grp.Exp(g, grp.Q())), "Base point of order q")
+
+	g3 := grp.Mul(grp.Mul(g, g), g)
+	assert.True(t, grp.Equal(g3, grp.Exp(g, big.NewInt(3))), "Scalar multiplication")
+	assert.True(t, grp.Equal(grp.One(), grp.Mul(g3, grp.Inv(g3))), "Inverse")
+	p, _ := grp.Parse(g3.String())
+	assert.True(t, grp.Equal(g3, p), "Encoding roundtrip")
+
+	assert.False(t, grp.IsMember(grp.One()), "Neutral element")
+	_, err = grp.Parse("5866")
+	assert.NotEqual(t, nil, err, "Bad encoding")
+
+	// RFC 8032 keys and crypto/ed25519 signatures, [S]G = R + [k]A
+	for _, key := range [][2]string{ // RFC 8032 tests 1 to 3, secret and public keys
+		{"9d61b19deffd5a60ba844af492ec2cc44449c5697b326919703bac031cae7f60", "d75a980182b10ab7d54bfed3c964073a0ee172f3daa62325af021a68f707511a"},
+		{"4ccd089b28ff96da9db6c346ec114e0f5b8a319f35aba624da8cf6ed4fb8a6fb", "3d4017c3e843895a92b70aa74d1b7ebc9c982ccf2ec4968cc0cd55f12af4660c"},
+		{"c5aa8df43f9f837bedb7442f31dcb7b166d38535076f094b85ce3a2e0b4458f7", "fc51cd8e6218a1a38da47ed00230f0580816ed13ba3303ac5deb911548908025"},
+	} {
+		raw, _ := hex.DecodeString(key[0])
+		sk := ed25519.NewKeyFromSeed(raw)
+		h := sha512.Sum512(raw)
+		h[0] &= 248
+		h[31] &= 127
+		h[31] |= 64
+		A := grp.Exp(g, leInt(h[:32]))
+		assert.Equal(t, key[1], A.String(), "Public key encoding")
+		assert.Equal(t, key[1], hex.EncodeToString(sk.Public().(ed25519.PublicKey)), "crypto/ed25519 public key")
+
+		msg := []byte("borvo")
+		sig := ed25519.Sign(sk, msg)
+		R, err := grp.Parse(hex.EncodeToString(sig[:32]))
+		assert.Equal(t, nil, err, "Parse R")
+		k := sha512.Sum512(append(append(append([]byte{}, sig[:32]...), sk[32:]...), msg...))
+		kA := grp.Exp(A, new(big.Int).Mod(leInt(k[:]), grp.Q()))
+		assert.True(t, grp.Equal(grp.Exp(g, leInt(sig[32:])), grp.Mul(R, kA)), "Signature equation")
+	}
+}
+
+func TestEd25519Ballots(t *testing.T) {
+	elec, _, ballots, _ := readTestData(t)
+
+	// Blank and non-blank answers accepted with Belenios ballots
+	tg := newTestGroup(elec)
+	y, _ := tg.Parse(elec.PublicKey.Y)
+	HJSON := Fingerprint(elec)
+	S, _ := tg.Parse(ballots[0].Signature.PublicKey)
+	for _, choice := range []int{-1, 2} {
+		a := tg.hAnswer(y, S, elec.Questions[0], choice)
+		assert.Equal(t, nil, verifyBallotBlankProofs(Ballot{Answers: []Answer{a}, Signature: ballots[0].Signature}, elec), "Blank proof")
+		assert.Equal(t, nil, verifyBallotOverallProofs(Ballot{Answers: []Answer{a}, Signature: ballots[0].Signature}, elec), "Overall proof")
+	}
+
+	// Election in Ed25519 group, with and without blank
+	json.Unmarshal([]byte(`"Ed25519"`), &elec.PublicKey.Group)
+	elec.Questions = elec.Questions[:2]
+	elec.Fingerprint = ""
+	tg = newTestGroup(elec)
+	g := tg.G()
+	x := tg.random()
+	y = tg.Exp(g, x)
+	elec.PublicKey.Y = y.String()
+	HJSON = Fingerprint(elec)
+	trustees := []Trustee{{Kind: "Single", Single: &TrusteePublicKey{}}}
+	*trustees[0].Single = tg.publicKey(x)
+
+	votes := [][2]int{{-1, 0}, {0, 2}, {2, 2}}
+	ballots = nil
+	for _, v := range votes {
+		sk := tg.random()
+		S := tg.Exp(g, sk)
+		b := Ballot{ElectionHash: HJSON, ElectionUUID: elec.UUID}
+		b.Answers = []Answer{tg.hAnswer(y, S, elec.Questions[0], v[0]), tg.hAnswer(y, S, elec.Questions[1], v[1])}
+		tg.signBallot(sk, &b)
+		assert.Equal(t, nil, verifyBallot(b, elec, HJSON), "verifyBallot Ed25519")
+		ballots = append(ballots, b)
+	}
+	bad := ballots[0]
+	bad.Answers = []Answer{ballots[1].Answers[0], ballots[0].Answers[1]}
+	assert.NotEqual(t, nil, verifyBallot(bad, elec, HJSON), "Answer of an other ballot")
+
+	// Tally decrypted by the trustee
+	res := Result{NumTallied: len(ballots), Result: [][]int{{1, 1, 0, 1}, {1, 0, 2}}}
+	pd := PartialDecryption{}
+	for i, cs := range tallyCiphertexts(Count(elec, ballots, nil)) {
+		var fs []string
+		var proofs []Proof
+		res.EncryptedTally = append(res.EncryptedTally, nil)
+		for _, c := range cs {
+			res.EncryptedTally[i] = append(res.EncryptedTally[i], c)
+			alpha, _ := tg.Parse(c.Alpha)
+			fs = append(fs, tg.Exp(alpha, x).String())
+			proofs = append(proofs, tg.prove(x, fmt.Sprintf("decrypt|%s|", y), g, alpha))
+		}
+		pd.DecryptionFactors = append(pd.DecryptionFactors, fs)
+		pd.DecryptionProofs = append(pd.DecryptionProofs, proofs)
+	}
+	res.PartialDecryptions = []PartialDecryption{pd}
+	v := Verifier{Election: elec, Trustees: trustees}
+	r := v.Verify(ballots, res)
+	assert.Equal(t, nil, r.Err(), "Verify Ed25519 election")
+	assert.Equal(t, res.Result, r.Results, "Ed25519 results")
+}
+
+// Little endian integer
+func leInt(b []byte) *big.Int {
+	be := make([]byte, len(b))
+	for i := range b {
+		be[len(b)-1-i] = b[i]
+	}
+	return new(big.Int).SetBytes(be)
+}
@@ -0,0 +1,132 @@
+package belenios
+
+import (
+	"html/template"
+	"io"
+)
+
+type htmlReport struct {
+	AuditReport
+	Group     string
+	Questions []ResultTable
+}
+
+// Write a static html audit report, readable offline
+func WriteHTMLReport(w io.Writer, a AuditReport, elec Election, res Result) error {
+	h := htmlReport{
+		AuditReport: a,
+		Group:       a.Election.Group,
+		Questions:   ResultTables(elec, res, a.Results.Decrypted),
+	}
+	if h.Group == "" {
+		h.Group = "custom group"
+	}
+	return htmlTemplate.Execute(w, h)
+}
+
+var htmlTemplate = template.Must(template.New("report").Funcs(template.FuncMap{
+	"inc": func(i int) int { return i + 1 },
+}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>Audit report - {{.Election.Name}}</title>
+<style>
+body { font-family: sans-serif; max-width: 60em; margin: 2em auto; padding: 0 1em; color: #222; }
+table { border-collapse: collapse; margin: 1em 0; }
+th, td { border: 1px solid #999; padding: .3em .6em; text-align: left; vertical-align: top; }
+th { background: #eee; }
+td.n { text-align: right; }
+.mono { font-family: monospace; word-break: break-all; }
+.ok { color: #060; font-weight: bold; }
+.ko { color: #a00; font-weight: bold; }
+.verdict { padding: .6em 1em; border: 2px solid; font-size: 1.2em; }
+pre { white-space: pre-wrap; word-break: break-all; margin: 0; }
+</style>
+</head>
+<body>
+<h1>{{.Election.Name}}</h1>
+<p>{{.Election.Description}}</p>
+
+{{if .Passed}}<p class="verdict ok">All checks verified</p>{{else}}<p class="verdict ko">Verification failed</p>{{end}}
+
+<h2>Election</h2>
+<table>
+<tr><th>ID</th><td class="mono">{{.Election.UUID}}</td></tr>
+<tr><th>Administrator</th><td>{{.Election.Administrator}}</td></tr>
+<tr><th>Credential authority</th><td>{{.Election.CredentialAuthority}}</td></tr>
+<tr><th>Fingerprint</th><td class="mono">{{.Election.Fingerprint}}</td></tr>
+<tr><th>Group</th><td>{{.Group}}</td></tr>
+<tr><th>Questions</th><td>{{len .Election.Questions}}</td></tr>
+<tr><th>Ballots</th><td>{{len .Ballots}}, {{.Counted}} counted{{if .TotalWeight}}, total weight {{.TotalWeight}}{{end}}</td></tr>
+{{- with .Credentials}}
+<tr><th>Credentials</th><td>{{.Used}} used, {{.Unused}} unused</td></tr>
+{{- else}}
+<tr><th>Credentials</th><td class="ko">no public credentials, not verified</td></tr>
+{{- end}}
+</table>
+{{- range .Election.Ignored}}
+<p class="ko">Unknown field {{.}} in election.json, not verified</p>
+{{- end}}
+
+<h2>Checks</h2>
+<p>Sections of the <a href="https://www.belenios.org/specification.pdf">Belenios specification</a>.</p>
+<table>
+<tr><th>Phase</th><th>Check</th><th>Section</th><th>Passed</th><th>Failed</th></tr>
+{{- range .Checks}}
+<tr><td>{{.Phase}}</td><td>{{.Name}}</td><td>{{if .Spec}}[{{.Spec}}]{{end}}</td><td class="n">{{.Passed}}</td><td class="n{{if .Failed}} ko{{end}}">{{.Failed}}</td></tr>
+{{- end}}
+</table>
+
+<h2>Failures</h2>
+{{- if .Failures}}
+<table>
+<tr><th>Ballot</th><th>Tracker</th><th>Question</th><th>Check</th><th>Error</th></tr>
+{{- range .Failures}}
+<tr><td>{{if .Ballot}}{{.Ballot}}{{else}}{{.Phase}}{{end}}</td><td class="mono">{{.Tracker}}</td><td>{{if .Question}}{{.Question}}{{end}}</td><td>{{.Name}}</td><td><pre>{{.Error}}</pre></td></tr>
+{{- end}}
+</table>
+{{- else}}
+<p class="ok">None</p>
+{{- end}}
+
+<h2>Results</h2>
+{{- if not .Results.Decrypted}}
+<p class="ko">Results not decrypted</p>
+{{- else if not .Results.Match}}
+<p class="ko">Decrypted results do not match result.json</p>
+{{- end}}
+{{- range .Questions}}
+<h3>{{.Question}}</h3>
+{{- if .Bounds}}
+<p>({{.Bounds}})</p>
+{{- end}}
+{{- if .Rows}}
+<table>
+<tr><th>Answer</th><th>Votes</th></tr>
+{{- range .Rows}}
+<tr><td>{{.Answer}}</td><td class="n">{{.Count}}</td></tr>
+{{- end}}
+</table>
+{{- else}}
+<table>
+<tr><th>Ballot</th><th>Decrypted (non-homomorphic, {{len .Ballots}} ballots)</th></tr>
+{{- range $i, $b := .Ballots}}
+<tr><td class="n">{{inc $i}}</td><td>{{$b}}</td></tr>
+{{- end}}
+</table>
+{{- end}}
+{{- end}}
+
+<h2>Ballots trackers</h2>
+<table>
+<tr><th>#</th><th>Tracker</th><th></th></tr>
+{{- range .Ballots}}
+<tr><td class="n">{{.Index}}</td><td class="mono">{{.Tracker}}</td><td>{{if .Passed}}<span class="ok">OK</span>{{else}}<span class="ko">KO</span>{{end}}</td></tr>
+{{- end}}
+</table>
+
+<p><small>Verified with borvo {{.Version}}</small></p>
+</body>
+</html>
+`))
@@ -0,0 +1,72 @@
+package belenios
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Mixnet for non-homomorphic questions
+//
+// Proofs of shuffle of Belenios mix servers are not verified: the
+// non-homomorphic tally is decrypted and checked against result.json,
+// but not linked to the ballots.
+
+// Shuffles from ballots to encrypted tally, not supported
+func verifyShuffles(elec Election) error {
+	var questions []string
+	for i, q := range elec.Questions {
+		if q.Type == QuestionNonHomomorphic {
+			questions = append(questions, fmt.Sprint(i+1))
+		}
+	}
+	return fmt.Errorf(" Mixnet shuffles not supported\n  tally of questions %s not linked to ballots\n", strings.Join(questions, ", "))
+}
+
+// Plaintext of a non-homomorphic answer, Belenios G.of_ints
+func nhPlaintext(grp Group, question Question, xs []int) (Element, error) {
+	if len(xs) != len(question.Answers) {
+		return nil, fmt.Errorf("%d values for %d answers", len(xs), len(question.Answers))
+	}
+	return grp.OfInts(xs)
+}
+
+// Verify non-homomorphic results are the decrypted last shuffle
+func verifyNonHomomorphicResults(elec Election, res Result, trustees []Trustee) error {
+	grp := electionGroup(elec)
+
+	// Combined decryption factors, verified by verifyDecryptionFactors
+	factors, err := combineFactors(elec, res, trustees)
+	if err != nil {
+		return err
+	}
+
+	for i, question := range elec.Questions {
+		if question.Type != QuestionNonHomomorphic {
+			continue
+		}
+		var results [][]int
+		if i < len(res.NonHomomorphicResult) {
+			results = res.NonHomomorphicResult[i]
+		}
+		if i >= len(res.EncryptedTally) || i >= len(factors) {
+			return questionErrorf(i, " Missing encrypted tally for question %d\n", i+1)
+		}
+		if len(results) != len(res.EncryptedTally[i]) {
+			return questionErrorf(i, " Non-homomorphic result question %d\n  %d ballots for %d ciphertexts\n", i+1, len(results), len(res.EncryptedTally[i]))
+		}
+		for j, c := range res.EncryptedTally[i] {
+			// m = beta/f
+			beta, _ := grp.Parse(c.Beta)
+			M := grp.Mul(beta, grp.Inv(factors[i][j]))
+
+			expected, err := nhPlaintext(grp, question, results[j])
+			if err != nil {
+				return questionErrorf(i, " Non-homomorphic result question %d, ballot %d\n  %s\n", i+1, j+1, err)
+			}
+			if !grp.Equal(M, expected) {
+				return questionErrorf(i, " Non-homomorphic result question %d, ballot %d\n  %v is not the decrypted ciphertext\n", i+1, j+1, results[j])
+			}
+		}
+	}
+	return nil // no error
+}
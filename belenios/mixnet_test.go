@@ -0,0 +1,100 @@
+package belenios
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMixnet(t *testing.T) {
+	elec, _, _, _ := readTestData(t)
+	json.Unmarshal([]byte(`"RFC-3526-2048"`), &elec.PublicKey.Group)
+	testMixnet(t, elec)
+}
+
+func TestOfInts(t *testing.T) {
+	elec, _, _, _ := readTestData(t)
+	_, err := electionGroup(elec).OfInts([]int{1})
+	assert.Contains(t, err.Error(), "safe prime group", "Belenios default group")
+
+	json.Unmarshal([]byte(`"RFC-3526-2048"`), &elec.PublicKey.Group)
+	grp := electionGroup(elec)
+	p, _ := new(big.Int).SetString(elec.PublicKey.Group.P, 10)
+	x, err := grp.OfInts([]int{1, 2, 3})
+	assert.Equal(t, nil, err, "OfInts")
+	assert.Equal(t, "66052", x.String(), "Quadratic residue")
+	x, _ = grp.OfInts([]int{3, 1, 2})
+	assert.Equal(t, new(big.Int).Sub(p, big.NewInt(196867)).String(), x.String(), "Opposite quadratic residue")
+	x, _ = grp.OfInts([]int{0, 0})
+	assert.Equal(t, "1", x.String(), "Zeros")
+	_, err = grp.OfInts([]int{256})
+	assert.Contains(t, err.Error(), "value 256 not in [0, 255]", "Large value")
+
+	_, err = ed25519Group{}.OfInts([]int{1})
+	assert.Contains(t, err.Error(), "not supported", "Ed25519")
+}
+
+func testMixnet(t *testing.T, elec Election) {
+	tg := newTestGroup(elec)
+	g := tg.G()
+
+	// Single trustee
+	x := tg.random()
+	trustees := []Trustee{{Kind: "Single", Single: &TrusteePublicKey{PublicKey: tg.Exp(g, x).String()}}}
+	y := tg.Exp(g, x)
+	elec.PublicKey.Y = y.String()
+
+	// Mixed election
+	nh := Question{Answers: []string{"A", "B", "C"}, Question: "Rank", Type: QuestionNonHomomorphic}
+	elec.Questions = append(elec.Questions[:1], nh)
+
+	votes := [][]int{{1, 2, 3}, {3, 1, 2}, {2, 3, 1}, {1, 3, 2}}
+	var in []Ciphertext
+	for _, v := range votes {
+		M, err := nhPlaintext(tg, nh, v)
+		assert.Equal(t, nil, err, "nhPlaintext")
+		in = append(in, tg.nhAnswer(y, tg.G(), M).NonHomomorphic.Choices)
+	}
+
+	// Decryption of ballots ciphertexts, as after a shuffle
+	var res Result
+	res.EncryptedTally = make([][]struct {
+		Alpha string `json:"alpha"`
+		Beta  string `json:"beta"`
+	}, 2)
+	pd := PartialDecryption{DecryptionFactors: make([][]string, 2), DecryptionProofs: make([][]Proof, 2)}
+	res.NonHomomorphicResult = make([][][]int, 2)
+	for _, c := range in {
+		res.EncryptedTally[1] = append(res.EncryptedTally[1], c)
+		alpha, _ := tg.Parse(c.Alpha)
+		beta, _ := tg.Parse(c.Beta)
+		f := tg.Exp(alpha, x)
+		pd.DecryptionFactors[1] = append(pd.DecryptionFactors[1], f.String())
+		pd.DecryptionProofs[1] = append(pd.DecryptionProofs[1], tg.prove(x, fmt.Sprintf("decrypt|%s|", y), g, alpha))
+		M := tg.Mul(beta, tg.Inv(f))
+		for _, v := range votes {
+			E, _ := nhPlaintext(tg, nh, v)
+			if tg.Equal(E, M) {
+				res.NonHomomorphicResult[1] = append(res.NonHomomorphicResult[1], v)
+			}
+		}
+	}
+	res.PartialDecryptions = []PartialDecryption{pd}
+	res.Result = [][]int{{}, nil}
+
+	assert.Equal(t, nil, verifyDecryptionFactors(elec, res, trustees), "verifyDecryptionFactors")
+	assert.Equal(t, nil, verifyNonHomomorphicResults(elec, res, trustees), "verifyNonHomomorphicResults")
+	assert.ElementsMatch(t, votes, res.NonHomomorphicResult[1], "Decrypted ballots")
+	assert.Equal(t, " Mixnet shuffles not supported\n  tally of questions 2 not linked to ballots\n", verifyShuffles(elec).Error(), "Shuffles not verified")
+
+	// Wrong decrypted ballot
+	res.NonHomomorphicResult[1][0] = []int{3, 3, 3}
+	err := verifyNonHomomorphicResults(elec, res, trustees)
+	assert.Contains(t, err.Error(), "Non-homomorphic result question 2, ballot 1", "Wrong result")
+	res.NonHomomorphicResult[1][0] = []int{1, 2}
+	err = verifyNonHomomorphicResults(elec, res, trustees)
+	assert.Contains(t, err.Error(), "2 values for 3 answers", "Short result")
+}
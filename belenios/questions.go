@@ -0,0 +1,202 @@
+package belenios
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+// Question types
+const (
+	QuestionHomomorphic    = "Homomorphic"
+	QuestionNonHomomorphic = "NonHomomorphic"
+)
+
+// json of a tagged question, legacy homomorphic questions are not tagged
+//
+//	{"type":"NonHomomorphic","value":{"answers":[...],"question":"..."},"extra":...}
+type taggedQuestion struct {
+	Type  string          `json:"type"`
+	Value json.RawMessage `json:"value"`
+	Extra json.RawMessage `json:"extra,omitempty"`
+}
+
+// Read legacy homomorphic question or tagged question, other types
+// (as Lists) fail without supported proofs
+func (q *Question) UnmarshalJSON(data []byte) error {
+	type question Question // without UnmarshalJSON
+	var t taggedQuestion
+	if err := json.Unmarshal(data, &t); err != nil {
+		return err
+	}
+	*q = Question{}
+	switch t.Type {
+	case "":
+		if err := json.Unmarshal(data, (*question)(q)); err != nil {
+			return err
+		}
+		t.Type = QuestionHomomorphic
+	case QuestionHomomorphic, QuestionNonHomomorphic:
+		if err := json.Unmarshal(t.Value, (*question)(q)); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("question of type %s not supported", t.Type)
+	}
+	q.Type, q.Extra = t.Type, t.Extra
+	return nil
+}
+
+func (q Question) MarshalJSON() ([]byte, error) {
+	type question Question // without MarshalJSON
+	if q.Type == QuestionHomomorphic && q.Extra == nil {
+		return json.Marshal(question(q))
+	}
+	var v interface{} = question(q)
+	if q.Type == QuestionNonHomomorphic {
+		v = struct {
+			Answers  []string `json:"answers"`
+			Question string   `json:"question"`
+		}{q.Answers, q.Question}
+	}
+	value, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(taggedQuestion{Type: q.Type, Value: value, Extra: q.Extra})
+}
+
+// Read answer, a non-homomorphic answer has a single ciphertext
+//
+//	{"choices":{"alpha":"...","beta":"..."},"proof":{...}}
+func (a *Answer) UnmarshalJSON(data []byte) error {
+	var raw struct {
+		Choices json.RawMessage `json:"choices"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	if c := bytes.TrimSpace(raw.Choices); len(c) > 0 && c[0] == '{' {
+		*a = Answer{NonHomomorphic: new(NHAnswer)}
+		return json.Unmarshal(data, a.NonHomomorphic)
+	}
+	type answer Answer // without UnmarshalJSON
+	return json.Unmarshal(data, (*answer)(a))
+}
+
+func (a Answer) MarshalJSON() ([]byte, error) {
+	if a.NonHomomorphic != nil {
+		return json.Marshal(a.NonHomomorphic)
+	}
+	type answer Answer // without MarshalJSON
+	return json.Marshal(answer(a))
+}
+
+// Ciphertexts of an answer, in ballot signature order
+func (a Answer) Ciphertexts() []Ciphertext {
+	if a.NonHomomorphic != nil {
+		return []Ciphertext{a.NonHomomorphic.Choices}
+	}
+	return a.Choices
+}
+
+// Check answers match questions kinds
+func verifyBallotAnswers(b Ballot, elec Election) error {
+	if len(b.Answers) != len(elec.Questions) {
+		return fmt.Errorf(" Ballot with %d answers\n  for %d questions\n", len(b.Answers), len(elec.Questions))
+	}
+	for i, a := range b.Answers {
+		q := elec.Questions[i]
+		switch q.Type {
+		case QuestionNonHomomorphic:
+			if a.NonHomomorphic == nil {
+				return questionErrorf(i, " Ballot answer %d\n  not matching question type\n", i+1)
+			}
+			if q.Blank {
+				return questionErrorf(i, " Ballot answer %d\n  blank on non-homomorphic question\n", i+1)
+			}
+		default: // QuestionHomomorphic
+			if a.NonHomomorphic != nil {
+				return questionErrorf(i, " Ballot answer %d\n  not matching question type\n", i+1)
+			}
+			n := len(q.Answers)
+			if q.Blank {
+				n++
+			}
+			if len(a.Choices) != n || len(a.IndividualProofs) != n {
+				return questionErrorf(i, " Ballot answer %d\n  %d choices for %d answers\n", i+1, len(a.Choices), n)
+			}
+			for _, ind := range a.IndividualProofs {
+				if len(ind) != 2 {
+					return questionErrorf(i, " Ballot answer %d\n  %d individual proofs for 0..1\n", i+1, len(ind))
+				}
+			}
+			if len(a.OverallProof) != n-len(q.Answers)+q.Max-q.Min+1 {
+				return questionErrorf(i, " Ballot answer %d\n  %d overall proofs\n", i+1, len(a.OverallProof))
+			}
+			if q.Blank && len(a.BlankProof) != 2 {
+				return questionErrorf(i, " Ballot answer %d\n  %d blank proofs\n", i+1, len(a.BlankProof))
+			}
+		}
+	}
+	return nil // no error
+}
+
+// Is there a non-homomorphic question
+func HasNonHomomorphic(elec Election) bool {
+	for _, q := range elec.Questions {
+		if q.Type == QuestionNonHomomorphic {
+			return true
+		}
+	}
+	return false
+}
+
+// Read results, a non-homomorphic question result is a list of decrypted ballots
+//
+//	"result":[[1,2,0],[[1,2],[2,1]]]
+func (r *Result) UnmarshalJSON(data []byte) error {
+	type result Result // without UnmarshalJSON
+	var raw struct {
+		result
+		Result []json.RawMessage `json:"result"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	*r = Result(raw.result)
+	r.Result = nil
+	for i, q := range raw.Result {
+		var count []int
+		if err := json.Unmarshal(q, &count); err == nil {
+			r.Result = append(r.Result, count)
+			continue
+		}
+		var ballots [][]int
+		if err := json.Unmarshal(q, &ballots); err != nil {
+			return fmt.Errorf("result of question %d: %s", i+1, err)
+		}
+		if r.NonHomomorphicResult == nil {
+			r.NonHomomorphicResult = make([][][]int, len(raw.Result))
+		}
+		r.NonHomomorphicResult[i] = ballots
+		r.Result = append(r.Result, nil)
+	}
+	return nil
+}
+
+func (r Result) MarshalJSON() ([]byte, error) {
+	type result Result // without MarshalJSON
+	out := struct {
+		result
+		Result []interface{} `json:"result"`
+	}{result: result(r)}
+	for i, count := range r.Result {
+		if i < len(r.NonHomomorphicResult) && r.NonHomomorphicResult[i] != nil {
+			out.Result = append(out.Result, r.NonHomomorphicResult[i])
+		} else {
+			out.Result = append(out.Result, count)
+		}
+	}
+	return json.Marshal(out)
+}
@@ -0,0 +1,248 @@
+package belenios
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Non-homomorphic answer encrypting M, for ballot with signature key S
+func (tg testGroup) nhAnswer(y, S, M Element) Answer {
+	r := tg.random()
+	alpha := tg.Exp(tg.G(), r)
+	beta := tg.Mul(tg.Exp(y, r), M)
+
+	// A = g**w, response = w - r*challenge
+	w := tg.random()
+	A := tg.Exp(tg.G(), w)
+	c := hashQ(fmt.Sprintf("raweg|%s|%s,%s,%s|%s", S, y, alpha, beta, A), tg.Q())
+	resp := new(big.Int).Mul(r, c)
+	resp = resp.Sub(w, resp).Mod(resp, tg.Q())
+
+	return Answer{NonHomomorphic: &NHAnswer{
+		Choices: Ciphertext{Alpha: alpha.String(), Beta: beta.String()},
+		Proof:   Proof{Challenge: c.String(), Response: resp.String()},
+	}}
+}
+
+// Proof that (alpha, beta) = (g**r, y**r g**m) with m in [lo, hi]
+func (tg testGroup) intervalProof(y, S, alpha, beta Element, r *big.Int, m, lo, hi int) []Proof {
+	g, q := tg.G(), tg.Q()
+	proofs := make([]Proof, hi-lo+1)
+	commitments := make([]string, 0, 2*len(proofs))
+	w := tg.random()
+	sum := big.NewInt(0)
+	for k := lo; k <= hi; k++ {
+		if k == m {
+			commitments = append(commitments, tg.Exp(g, w).String(), tg.Exp(y, w).String())
+			continue
+		}
+		// A = g**response / alpha**challenge, B = y**response / (beta/g**k)**challenge
+		c, resp := tg.random(), tg.random()
+		bk := tg.Mul(beta, tg.Inv(tg.Exp(g, big.NewInt(int64(k)))))
+		A := tg.Mul(tg.Exp(g, resp), tg.Inv(tg.Exp(alpha, c)))
+		B := tg.Mul(tg.Exp(y, resp), tg.Inv(tg.Exp(bk, c)))
+		commitments = append(commitments, A.String(), B.String())
+		proofs[k-lo] = Proof{Challenge: c.String(), Response: resp.String()}
+		sum.Add(sum, c)
+	}
+	h := hashQ(fmt.Sprintf("prove|%s|%s,%s|%s", S, alpha, beta, strings.Join(commitments, ",")), q)
+	c := h.Sub(h, sum).Mod(h, q)
+	resp := new(big.Int).Mul(r, c)
+	proofs[m-lo] = Proof{Challenge: c.String(), Response: resp.Add(resp, w).Mod(resp, q).String()}
+	return proofs
+}
+
+// Homomorphic answer with one choice in [0, n), or -1 for a blank vote
+// to a question with blank
+func (tg testGroup) hAnswer(y, S Element, question Question, choice int) Answer {
+	var a Answer
+	var rs []*big.Int
+	n := len(question.Answers)
+	if question.Blank {
+		n++ // blank choice first
+		choice++
+	}
+	for i := 0; i < n; i++ {
+		m := 0
+		if i == choice {
+			m = 1
+		}
+		r := tg.random()
+		alpha := tg.Exp(tg.G(), r)
+		beta := tg.Mul(tg.Exp(y, r), tg.Exp(tg.G(), big.NewInt(int64(m))))
+		a.Choices = append(a.Choices, Ciphertext{Alpha: alpha.String(), Beta: beta.String()})
+		a.IndividualProofs = append(a.IndividualProofs, tg.intervalProof(y, S, alpha, beta, r, m, 0, 1))
+		rs = append(rs, r)
+	}
+	if !question.Blank {
+		aSum, bSum, rSum := tg.sum(a.Choices, rs)
+		a.OverallProof = tg.intervalProof(y, S, aSum, bSum, rSum, 1, question.Min, question.Max)
+		return a
+	}
+
+	// Blank proof: choice 0 or sum of the others is 0, overall proof:
+	// choice 0 is 1 or sum of the others in [min, max]
+	g := tg.G()
+	a0, _ := tg.Parse(a.Choices[0].Alpha)
+	b0, _ := tg.Parse(a.Choices[0].Beta)
+	aSum, bSum, rSum := tg.sum(a.Choices[1:], rs[1:])
+	P := fmt.Sprintf("%s,%s,%s,%s,%s,%s", g, y, a0, b0, aSum, bSum)
+	blank, overall := 0, 1 // real statements, others simulated
+	witness := [2]*big.Int{rs[0], rSum}
+	if choice == 0 {
+		blank, overall = 1, 0
+		witness = [2]*big.Int{rSum, rs[0]}
+	}
+	a.BlankProof = tg.orProof(y, fmt.Sprintf("bproof0|%s|%s|", S, P), [][2]Element{{a0, b0}, {aSum, bSum}}, blank, witness[0])
+	pairs := [][2]Element{{a0, tg.Mul(b0, tg.Inv(g))}}
+	for k := question.Min; k <= question.Max; k++ {
+		pairs = append(pairs, [2]Element{aSum, tg.Mul(bSum, tg.Inv(tg.Exp(g, big.NewInt(int64(k)))))})
+	}
+	if overall == 1 {
+		overall += 1 - question.Min // sum is 1
+	}
+	a.OverallProof = tg.orProof(y, fmt.Sprintf("bproof1|%s|%s|", S, P), pairs, overall, witness[1])
+	return a
+}
+
+// Products of ciphertexts and sum of their randoms
+func (tg testGroup) sum(cs []Ciphertext, rs []*big.Int) (Element, Element, *big.Int) {
+	aSum, bSum, rSum := tg.One(), tg.One(), big.NewInt(0)
+	for i, c := range cs {
+		alpha, _ := tg.Parse(c.Alpha)
+		beta, _ := tg.Parse(c.Beta)
+		aSum, bSum, rSum = tg.Mul(aSum, alpha), tg.Mul(bSum, beta), rSum.Add(rSum, rs[i])
+	}
+	return aSum, bSum, rSum
+}
+
+// Proof that one of (alpha, beta) pairs is (g**r, y**r), with
+// A = g**response alpha**challenge, B = y**response beta**challenge
+func (tg testGroup) orProof(y Element, prefix string, pairs [][2]Element, real int, r *big.Int) []Proof {
+	g, q := tg.G(), tg.Q()
+	proofs := make([]Proof, len(pairs))
+	commitments := make([]string, 0, 2*len(pairs))
+	w := tg.random()
+	sum := big.NewInt(0)
+	for k, p := range pairs {
+		if k == real {
+			commitments = append(commitments, tg.Exp(g, w).String(), tg.Exp(y, w).String())
+			continue
+		}
+		c, resp := tg.random(), tg.random()
+		A := tg.Mul(tg.Exp(g, resp), tg.Exp(p[0], c))
+		B := tg.Mul(tg.Exp(y, resp), tg.Exp(p[1], c))
+		commitments = append(commitments, A.String(), B.String())
+		proofs[k] = Proof{Challenge: c.String(), Response: resp.String()}
+		sum.Add(sum, c)
+	}
+	h := hashQ(prefix+strings.Join(commitments, ","), q)
+	c := h.Sub(h, sum).Mod(h, q)
+	resp := new(big.Int).Mul(r, c)
+	proofs[real] = Proof{Challenge: c.String(), Response: resp.Sub(w, resp).Mod(resp, q).String()}
+	return proofs
+}
+
+// Sign ballot with credential secret key sk
+func (tg testGroup) signBallot(sk *big.Int, b *Ballot) {
+	pk := tg.Exp(tg.G(), sk)
+	var ciphers []string
+	for _, a := range b.Answers {
+		for _, c := range a.Ciphertexts() {
+			ciphers = append(ciphers, c.Alpha, c.Beta)
+		}
+	}
+	w := tg.random()
+	A := tg.Exp(tg.G(), w)
+	c := hashQ(fmt.Sprintf("sig|%s|%s|%s", pk, A, strings.Join(ciphers, ",")), tg.Q())
+	r := new(big.Int).Mul(sk, c)
+	r = r.Sub(w, r).Mod(r, tg.Q())
+	b.Signature.PublicKey = pk.String()
+	b.Signature.Challenge = c.String()
+	b.Signature.Response = r.String()
+}
+
+func TestNonHomomorphic(t *testing.T) {
+	elec, _, ballots, _ := readTestData(t)
+
+	// Mixed election
+	var q Question
+	err := json.Unmarshal([]byte(`{"type":"NonHomomorphic","value":{"answers":["A","B","C"],"question":"Rank"}}`), &q)
+	assert.Equal(t, nil, err, "Read non-homomorphic question")
+	assert.Equal(t, QuestionNonHomomorphic, q.Type, "Non-homomorphic question")
+	assert.Equal(t, []string{"A", "B", "C"}, q.Answers, "Non-homomorphic answers")
+	j, _ := json.Marshal(q)
+	assert.Equal(t, `{"type":"NonHomomorphic","value":{"answers":["A","B","C"],"question":"Rank"}}`, string(j), "Write non-homomorphic question")
+	elec.Questions = append(elec.Questions, q)
+	assert.Equal(t, true, HasNonHomomorphic(elec), "HasNonHomomorphic")
+	tg := newTestGroup(elec)
+	y, _ := tg.Parse(elec.PublicKey.Y)
+
+	// Mixed ballot, homomorphic proofs depend on the credential
+	b := ballots[0]
+	S, _ := tg.Parse(b.Signature.PublicKey)
+	b.Answers = append(append([]Answer{}, b.Answers...), tg.nhAnswer(y, S, tg.G()))
+	assert.Equal(t, nil, verifyBallotAnswers(b, elec), "verifyBallotAnswers mixed")
+	assert.Equal(t, nil, verifyBallotGroupMembership(b, elec), "verifyBallotGroupMembership mixed")
+	assert.Equal(t, nil, verifyBallotBlankProofs(b, elec), "verifyBallotBlankProofs mixed")
+	assert.Equal(t, nil, verifyBallotOverallProofs(b, elec), "verifyBallotOverallProofs mixed")
+	assert.Equal(t, nil, verifyBallotIndividualProofs(b, elec), "verifyBallotIndividualProofs mixed")
+	assert.Equal(t, nil, verifyBallotNonHomomorphicProofs(b, elec), "verifyBallotNonHomomorphicProofs mixed")
+	assert.NotEqual(t, nil, verifyBallotSignature(b, elec), "Signature without non-homomorphic answer")
+
+	// Non-homomorphic only ballot
+	nhElec := elec
+	nhElec.Questions = []Question{q}
+	HJSON := Fingerprint(nhElec)
+	sk := tg.random()
+	nhBallot := Ballot{ElectionHash: HJSON, ElectionUUID: elec.UUID}
+	nhBallot.Answers = []Answer{tg.nhAnswer(y, tg.Exp(tg.G(), sk), tg.G())}
+	tg.signBallot(sk, &nhBallot)
+	assert.Equal(t, nil, verifyBallot(nhBallot, nhElec, HJSON), "verifyBallot non-homomorphic")
+
+	// json roundtrip
+	j, _ = json.Marshal(b)
+	var jb Ballot
+	err = json.Unmarshal(j, &jb)
+	assert.Equal(t, nil, err, "Read mixed ballot")
+	assert.Equal(t, b.Answers, jb.Answers, "Mixed ballot answers")
+
+	// Proof for an other ciphertext
+	bad := b
+	bad.Answers = append([]Answer{}, b.Answers...)
+	nh := *b.Answers[4].NonHomomorphic
+	nh.Choices = tg.nhAnswer(y, S, tg.G()).NonHomomorphic.Choices
+	bad.Answers[4] = Answer{NonHomomorphic: &nh}
+	err = verifyBallotNonHomomorphicProofs(bad, elec)
+	assert.Contains(t, err.Error(), "Non-homomorphic Proof answer 5", "Bad non-homomorphic proof")
+
+	// Blank on non-homomorphic question
+	blankElec := elec
+	blankElec.Questions = append([]Question{}, elec.Questions...)
+	blankElec.Questions[4].Blank = true
+	err = verifyBallotAnswers(b, blankElec)
+	assert.Contains(t, err.Error(), "Ballot answer 5\n  blank on non-homomorphic question", "Blank non-homomorphic")
+	assert.Equal(t, nil, verifyBallotBlankProofs(b, blankElec), "No blank proof for non-homomorphic answer")
+
+	// Homomorphic answer to non-homomorphic question
+	bad.Answers[4] = b.Answers[0]
+	err = verifyBallotAnswers(bad, elec)
+	assert.Contains(t, err.Error(), "Ballot answer 5\n  not matching question type", "Answer type")
+	bad.Answers = bad.Answers[:4]
+	err = verifyBallotAnswers(bad, elec)
+	assert.Contains(t, err.Error(), "4 answers\n  for 5 questions", "Missing answer")
+
+	// Mixed results
+	var res Result
+	err = json.Unmarshal([]byte(`{"num_tallied":2,"result":[[1,2],[[1,2,3],[3,2,1]]]}`), &res)
+	assert.Equal(t, nil, err, "Read mixed result")
+	assert.Equal(t, [][]int{{1, 2}, nil}, res.Result, "Homomorphic result")
+	assert.Equal(t, [][]int{{1, 2, 3}, {3, 2, 1}}, res.NonHomomorphicResult[1], "Non-homomorphic result")
+	j, _ = json.Marshal(res)
+	assert.Contains(t, string(j), `"result":[[1,2],[[1,2,3],[3,2,1]]]`, "Write mixed result")
+}
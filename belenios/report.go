@@ -0,0 +1,182 @@
+package belenios
+
+import (
+	"errors"
+	"strings"
+)
+
+// Audit report of an election verification, json for archives and dashboards
+type AuditReport struct {
+	Version        string            `json:"version"` // of the verification tool
+	Election       ElectionSummary   `json:"election"`
+	Passed         bool              `json:"passed"`
+	Checks         []CheckCount      `json:"checks"`
+	Failures       []CheckResult     `json:"failures"`
+	Ballots        []BallotResult    `json:"ballots"` // by index
+	Credentials    *CredentialsUsage `json:"credentials,omitempty"`
+	Duplicates     []Duplicate       `json:"duplicates"`
+	Counted        int               `json:"counted"`
+	TotalWeight    int               `json:"total_weight,omitempty"`
+	EncryptedTally [][]Ciphertext    `json:"encrypted_tally"` // recomputed
+	Results        ResultsComparison `json:"results"`
+}
+
+// Election metadata, Group is empty for a custom group
+type ElectionSummary struct {
+	UUID                string            `json:"uuid"`
+	Name                string            `json:"name"`
+	Description         string            `json:"description"`
+	Administrator       string            `json:"administrator"`
+	CredentialAuthority string            `json:"credential_authority"`
+	Fingerprint         string            `json:"fingerprint"`
+	Group               string            `json:"group"`
+	Questions           []QuestionSummary `json:"questions"`
+	Ignored             []string          `json:"ignored_fields"` // not verified
+}
+
+type QuestionSummary struct {
+	Question string `json:"question"`
+	Type     string `json:"type"`
+}
+
+// Pass/fail count of a check, Phase is setup, ballot or tally,
+// Spec is the section of Belenios specification
+type CheckCount struct {
+	Phase  string `json:"phase"`
+	Name   string `json:"name"`
+	Spec   string `json:"spec,omitempty"`
+	Passed int    `json:"passed"`
+	Failed int    `json:"failed"`
+}
+
+// Specification sections of checks, by phase/name
+var checkSpecs = map[string]string{
+	"ballot/Election fingerprint":     "4.14",
+	"ballot/Signature":                "4.13",
+	"ballot/Blank proofs":             "4.12",
+	"ballot/Overall proofs":           "4.12",
+	"ballot/Individual proofs":        "4.11",
+	"tally/Decryption proofs":         "4.16",
+	"tally/Ballots homomorphic count": "4.18",
+	"tally/Decrypted results":         "4.18",
+}
+
+// Check result, Question from 1 when about a question
+type CheckResult struct {
+	Phase    string `json:"phase"`
+	Ballot   int    `json:"ballot,omitempty"`
+	Tracker  string `json:"tracker,omitempty"`
+	Question int    `json:"question,omitempty"`
+	Name     string `json:"name"`
+	Passed   bool   `json:"passed"`
+	Error    string `json:"error,omitempty"`
+}
+
+type BallotResult struct {
+	Index   int           `json:"index"` // ballot number in ballots.jsons
+	Tracker string        `json:"tracker"`
+	Passed  bool          `json:"passed"`
+	Checks  []CheckResult `json:"checks"`
+}
+
+type CredentialsUsage struct {
+	Used   int `json:"used"`
+	Unused int `json:"unused"`
+}
+
+// Decrypted results compared to result.json
+type ResultsComparison struct {
+	Decrypted [][]int `json:"decrypted"`
+	Published [][]int `json:"published"`
+	Match     bool    `json:"match"`
+}
+
+// Audit report of a verification, credentials usage only with
+// credentials
+func NewAuditReport(version string, elec Election, res Result, r Report, credentials bool) AuditReport {
+	a := AuditReport{
+		Version:        version,
+		Election:       summarizeElection(elec),
+		Passed:         r.Err() == nil,
+		Checks:         []CheckCount{},
+		Failures:       []CheckResult{},
+		Ballots:        []BallotResult{},
+		Duplicates:     append([]Duplicate{}, r.Duplicates...),
+		Counted:        r.Counted,
+		TotalWeight:    r.TotalWeight,
+		EncryptedTally: r.EncryptedTally,
+		Results: ResultsComparison{
+			Decrypted: r.Results,
+			Published: res.Result,
+			Match:     r.Results != nil && verifyDecryptedResults(elec, r.Results, res.Result) == nil,
+		},
+	}
+	if credentials {
+		a.Credentials = &CredentialsUsage{Used: r.CredentialsUsed, Unused: r.CredentialsUnused}
+	}
+
+	counts := make(map[string]int) // index in a.Checks
+	add := func(c CheckResult) {
+		key := c.Phase + "/" + c.Name
+		i, ok := counts[key]
+		if !ok {
+			i = len(a.Checks)
+			counts[key] = i
+			a.Checks = append(a.Checks, CheckCount{Phase: c.Phase, Name: c.Name, Spec: checkSpecs[key]})
+		}
+		if c.Passed {
+			a.Checks[i].Passed++
+		} else {
+			a.Checks[i].Failed++
+			a.Failures = append(a.Failures, c)
+		}
+	}
+
+	for _, c := range r.Setup {
+		add(checkResult("setup", c))
+	}
+	for _, b := range r.Ballots {
+		br := BallotResult{Index: b.Index, Tracker: b.Tracker, Passed: b.Err() == nil}
+		for _, c := range b.Checks {
+			cr := checkResult("ballot", c)
+			cr.Ballot, cr.Tracker = b.Index, b.Tracker
+			add(cr)
+			br.Checks = append(br.Checks, cr)
+		}
+		a.Ballots = append(a.Ballots, br)
+	}
+	for _, c := range r.Tally {
+		add(checkResult("tally", c))
+	}
+	return a
+}
+
+func checkResult(phase string, c Check) CheckResult {
+	cr := CheckResult{Phase: phase, Name: c.Name, Passed: c.Err == nil}
+	if c.Err != nil {
+		cr.Error = strings.TrimSpace(c.Err.Error())
+		var qe QuestionError
+		if errors.As(c.Err, &qe) {
+			cr.Question = qe.Question
+		}
+	}
+	return cr
+}
+
+func summarizeElection(elec Election) ElectionSummary {
+	s := ElectionSummary{
+		UUID:                elec.UUID,
+		Name:                elec.Name,
+		Description:         elec.Description,
+		Administrator:       elec.Administrator,
+		CredentialAuthority: elec.CredentialAuthority,
+		Fingerprint:         Fingerprint(elec),
+		Group:               GroupName(elec),
+		Questions:           []QuestionSummary{},
+		Ignored:             append([]string{}, elec.Ignored...),
+	}
+	for _, q := range elec.Questions {
+		s.Questions = append(s.Questions, QuestionSummary{Question: q.Question, Type: q.Type})
+	}
+	return s
+}
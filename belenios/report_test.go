@@ -0,0 +1,89 @@
+package belenios
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAuditReport(t *testing.T) {
+	elec, res, ballots, trustees := readTestData(t)
+	_, creds, _, _ := ReadCredentials("../dataTest")
+
+	v := Verifier{Election: elec, Trustees: trustees, Credentials: creds}
+	a := NewAuditReport("v0.3", elec, res, v.Verify(ballots, res), true)
+	assert.Equal(t, true, a.Passed, "Passed")
+	assert.Equal(t, "v0.3", a.Version, "Tool version")
+	assert.Equal(t, "e1Jmque3h7bkC6gz/6mrSlOL/88MHP6L6wNGuDsRbHE", a.Election.Fingerprint, "Fingerprint")
+	assert.Equal(t, len(elec.Questions), len(a.Election.Questions), "Questions")
+	assert.Equal(t, CheckCount{Phase: "ballot", Name: "Signature", Spec: "4.13", Passed: len(ballots)}, a.Checks[6+3], "Ballot check count")
+	assert.Equal(t, 0, len(a.Failures), "No failure")
+	assert.Equal(t, len(ballots), len(a.Ballots), "Ballots")
+	assert.Equal(t, 2, a.Ballots[1].Index, "Ballot index")
+	assert.Equal(t, ballots[1].Tracker, a.Ballots[1].Tracker, "Ballot tracker")
+	assert.Equal(t, &CredentialsUsage{Used: 3, Unused: 2}, a.Credentials, "Credentials usage")
+	for i, q := range res.EncryptedTally {
+		for j, c := range q {
+			assert.Equal(t, c.Alpha, a.EncryptedTally[i][j].Alpha, "Recomputed alpha")
+			assert.Equal(t, c.Beta, a.EncryptedTally[i][j].Beta, "Recomputed beta")
+		}
+	}
+	assert.Equal(t, ResultsComparison{Decrypted: res.Result, Published: res.Result, Match: true}, a.Results, "Results")
+
+	raw, err := json.Marshal(a)
+	assert.Equal(t, nil, err, "json report")
+	var back AuditReport
+	assert.Equal(t, nil, json.Unmarshal(raw, &back), "read json report")
+	assert.Equal(t, a.Ballots, back.Ballots, "json ballots")
+
+	// Failures with question
+	_, _, bad, _ := readTestData(t)
+	bad[2].Answers[1].IndividualProofs[0][0].Challenge = "1"
+	v.All = true
+	a = NewAuditReport("v0.3", elec, res, v.Verify(bad, res), true)
+	assert.Equal(t, false, a.Passed, "Failed")
+	assert.Equal(t, false, a.Ballots[2].Passed, "Failed ballot")
+	assert.Equal(t, 2, a.Failures[0].Question, "Failed question")
+	assert.Equal(t, "Individual proofs", a.Failures[0].Name, "Failed check")
+	assert.Equal(t, false, a.Results.Match, "Results not verified")
+}
+
+func TestHTMLReport(t *testing.T) {
+	elec, res, ballots, trustees := readTestData(t)
+
+	v := Verifier{Election: elec, Trustees: trustees}
+	a := NewAuditReport("v0.3", elec, res, v.Verify(ballots, res), false)
+	var buf bytes.Buffer
+	assert.Equal(t, nil, WriteHTMLReport(&buf, a, elec, res), "WriteHTMLReport")
+	page := buf.String()
+	assert.Contains(t, page, "<h1>Name of the test election</h1>", "Election name")
+	assert.Contains(t, page, "e1Jmque3h7bkC6gz/6mrSlOL/88MHP6L6wNGuDsRbHE", "Fingerprint")
+	assert.Contains(t, page, "<td>Signature</td><td>[4.13]</td>", "Spec section")
+	assert.Contains(t, page, "All checks verified", "Verdict")
+	assert.Contains(t, page, "<tr><td>Blank</td><td class=\"n\">1</td></tr>", "Result table")
+	assert.Contains(t, page, ballots[2].Tracker, "Tracker")
+	assert.NotContains(t, page, "src=", "No external resource")
+
+	// Escaped failures
+	res.Result = [][]int{{0}}
+	a = NewAuditReport("<b>", elec, res, v.Verify(ballots, res), false)
+	buf.Reset()
+	assert.Equal(t, nil, WriteHTMLReport(&buf, a, elec, res), "WriteHTMLReport")
+	page = buf.String()
+	assert.Contains(t, page, "Verification failed", "Verdict")
+	assert.Contains(t, page, "<td>Decrypted results</td>", "Failure")
+	assert.Contains(t, page, "borvo &lt;b&gt;", "Escaped")
+
+	// Repeated ballot keeps both entries
+	v.Revote = true
+	repeated := append(append([]Ballot{}, ballots...), ballots[0])
+	a = NewAuditReport("v0.3", elec, res, v.Verify(repeated, res), false)
+	assert.Equal(t, 4, len(a.Ballots), "Repeated ballot")
+	assert.Equal(t, a.Ballots[0].Tracker, a.Ballots[3].Tracker, "Same tracker")
+	assert.Equal(t, 4, a.Ballots[3].Index, "Repeated ballot index")
+	buf.Reset()
+	assert.Equal(t, nil, WriteHTMLReport(&buf, a, elec, res), "WriteHTMLReport")
+	assert.Contains(t, buf.String(), "<td>4, 3 counted</td>", "Ballots count")
+}
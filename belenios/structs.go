@@ -0,0 +1,172 @@
+package belenios
+
+import "encoding/json"
+
+type Result struct {
+	NumTallied     int `json:"num_tallied"`
+	TotalWeight    int `json:"total_weight,omitempty"` // weighted elections
+	EncryptedTally [][]struct {
+		Alpha string `json:"alpha"`
+		Beta  string `json:"beta"`
+	} `json:"encrypted_tally"`
+	PartialDecryptions []PartialDecryption `json:"partial_decryptions"`
+	Result             [][]int             `json:"result"`
+	// decrypted ballots of non-homomorphic questions, nil for homomorphic
+	NonHomomorphicResult [][][]int `json:"-"`
+}
+
+// Partial decryption from one trustee,
+// Owner is the trustee number (0 when implied by order)
+type PartialDecryption struct {
+	Owner             int        `json:"-"`
+	DecryptionFactors [][]string `json:"decryption_factors"`
+	DecryptionProofs  [][]Proof  `json:"decryption_proofs"`
+}
+
+// Finite field group parameters,
+// or Name of an elliptic curve group ("Ed25519")
+type GroupParams struct {
+	G    string `json:"g"`
+	P    string `json:"p"`
+	Q    string `json:"q"`
+	Name string `json:"-"`
+}
+
+// Election parameters of legacy election.json
+type Election struct {
+	Description string `json:"description"`
+	Name        string `json:"name"`
+	PublicKey   struct {
+		Group GroupParams `json:"group"`
+		Y     string      `json:"y"`
+	} `json:"public_key"`
+	Questions           []Question `json:"questions"`
+	UUID                string     `json:"uuid"`
+	Administrator       string     `json:"administrator"`
+	CredentialAuthority string     `json:"credential_authority"`
+	Fingerprint         string     `json:"-"` // of raw election.json
+	Ignored             []string   `json:"-"` // unknown fields of election.json
+}
+
+// Question of Type Homomorphic, or NonHomomorphic (mixnet) with only
+// Question and Answers
+type Question struct {
+	Type     string          `json:"-"`
+	Answers  []string        `json:"answers"`
+	Blank    bool            `json:"blank,omitempty"`
+	Min      int             `json:"min"`
+	Max      int             `json:"max"`
+	Question string          `json:"question"`
+	Extra    json.RawMessage `json:"-"` // type specific parameters
+}
+
+type Ciphertext struct {
+	Alpha string `json:"alpha"`
+	Beta  string `json:"beta"`
+}
+
+// Ballot answer to a homomorphic question,
+// or NonHomomorphic answer with a single ciphertext
+type Answer struct {
+	Choices          []Ciphertext `json:"choices"`
+	IndividualProofs [][]Proof    `json:"individual_proofs"`
+	OverallProof     []Proof      `json:"overall_proof"`
+	BlankProof       []Proof      `json:"blank_proof"`
+	NonHomomorphic   *NHAnswer    `json:"-"`
+}
+
+// Answer to a non-homomorphic question,
+// proof of knowledge of the encryption randomness
+type NHAnswer struct {
+	Choices Ciphertext `json:"choices"`
+	Proof   Proof      `json:"proof"`
+}
+
+type Ballot struct {
+	Answers      []Answer `json:"answers"`
+	ElectionHash string   `json:"election_hash"`
+	ElectionUUID string   `json:"election_uuid"`
+	Signature    struct {
+		PublicKey string `json:"public_key"`
+		Challenge string `json:"challenge"`
+		Response  string `json:"response"`
+	} `json:"signature"`
+	Tracker string `json:"-"` // smart ballot tracker, from raw json
+}
+
+type Proof struct {
+	Challenge string `json:"challenge"`
+	Response  string `json:"response"`
+}
+
+type TrusteePublicKey struct {
+	Pok       Proof  `json:"pok"`
+	PublicKey string `json:"public_key"`
+	Name      string `json:"name,omitempty"`
+}
+
+// message signed by a trustee, message is a json string
+type SignedMsg struct {
+	Message   string `json:"message"`
+	Signature Proof  `json:"signature"`
+}
+
+// json message of a Pedersen cert
+type CertKeys struct {
+	Verification string `json:"verification"`
+	Encryption   string `json:"encryption"`
+}
+
+// json message of Pedersen coefexps
+type Coefexps struct {
+	Coefexps []string `json:"coefexps"`
+}
+
+type Pedersen struct {
+	Threshold        int                `json:"threshold"`
+	Certs            []SignedMsg        `json:"certs"`
+	Coefexps         []SignedMsg        `json:"coefexps"`
+	VerificationKeys []TrusteePublicKey `json:"verification_keys"`
+}
+
+// trustees.json entry: ["Single", {...}] or ["Pedersen", {...}]
+type Trustee struct {
+	Kind     string
+	Single   *TrusteePublicKey
+	Pedersen *Pedersen
+}
+
+// Event of an election archive, hashes are SHA256 hex of archive entries
+type ArchiveEvent struct {
+	Parent  *string `json:"parent"` // null for first event
+	Height  int     `json:"height"`
+	Type    string  `json:"type"`
+	Payload *string `json:"payload,omitempty"`
+}
+
+// Setup event payload
+type ArchiveSetup struct {
+	Election    string `json:"election"`
+	Trustees    string `json:"trustees"`
+	Credentials string `json:"credentials"`
+}
+
+// EncryptedTally event payload
+type ArchiveTally struct {
+	NumTallied     int    `json:"num_tallied"`
+	TotalWeight    int    `json:"total_weight,omitempty"`
+	EncryptedTally string `json:"encrypted_tally"`
+}
+
+// Election rebuilt from an archive
+type Archive struct {
+	Election    Election
+	Result      Result
+	Ballots     []Ballot
+	Trustees    []Trustee
+	Credentials []string
+	Weights     map[string]int
+	Shuffles    int // mixnet shuffle events, not verified
+	Events      int
+	LastEvent   string // hash of last event
+}
@@ -0,0 +1,208 @@
+package belenios
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+)
+
+// Key used to check a partial decryption
+type decryptionKey struct {
+	Name      string
+	PublicKey Element
+	Trustee   int // index in trustees.json
+	Index     int // 1-based index in Pedersen trustees, 0 for Single
+}
+
+// Read partial decryption
+//
+//	{"decryption_factors":...,"decryption_proofs":...}
+//	or threshold form {"owner":1,"payload":{"decryption_factors":...,"decryption_proofs":...}}
+func (pd *PartialDecryption) UnmarshalJSON(data []byte) error {
+	type partial PartialDecryption
+	var owned struct {
+		Owner   int              `json:"owner"`
+		Payload *json.RawMessage `json:"payload"`
+	}
+	if err := json.Unmarshal(data, &owned); err != nil {
+		return err
+	}
+	if owned.Payload == nil {
+		return json.Unmarshal(data, (*partial)(pd))
+	}
+	if err := json.Unmarshal(*owned.Payload, (*partial)(pd)); err != nil {
+		return err
+	}
+	pd.Owner = owned.Owner
+	return nil
+}
+
+func (pd PartialDecryption) MarshalJSON() ([]byte, error) {
+	type partial PartialDecryption
+	if pd.Owner == 0 {
+		return json.Marshal(partial(pd))
+	}
+	return json.Marshal(struct {
+		Owner   int     `json:"owner"`
+		Payload partial `json:"payload"`
+	}{pd.Owner, partial(pd)})
+}
+
+// Pedersen verification key of trustee j (1-based)
+//
+//	vk_j = product for each trustee i of product for k of coefexps_i,k**(j**k)
+func pedersenVerificationKey(p Pedersen, j int, elec Election) (Element, error) {
+	grp := electionGroup(elec)
+
+	vk := grp.One()
+	for i, c := range p.Coefexps {
+		var coefs Coefexps
+		json.Unmarshal([]byte(c.Message), &coefs)
+		if len(coefs.Coefexps) != p.Threshold {
+			return nil, fmt.Errorf("coefexps %d: %d coefexps for threshold %d", i+1, len(coefs.Coefexps), p.Threshold)
+		}
+		jk := big.NewInt(1) // j**k
+		for k, sc := range coefs.Coefexps {
+			C, err := grp.Parse(sc)
+			if err != nil {
+				return nil, fmt.Errorf("coefexps %d: bad coefexp %d", i+1, k+1)
+			}
+			vk = grp.Mul(vk, grp.Exp(C, jk))
+			jk = jk.Mul(jk, big.NewInt(int64(j)))
+		}
+	}
+	return vk, nil
+}
+
+// Keys of trustees in trustees.json order,
+// Pedersen trustees keys are derived from coefexps
+func decryptionKeys(trustees []Trustee, elec Election) ([]decryptionKey, error) {
+	grp := electionGroup(elec)
+	var keys []decryptionKey
+	for i, t := range trustees {
+		switch t.Kind {
+		case "Single":
+			X, err := grp.Parse(t.Single.PublicKey)
+			if err != nil {
+				return nil, fmt.Errorf(" Bad public key for trustee %s\n", t.Single.Label(i))
+			}
+			keys = append(keys, decryptionKey{Name: t.Single.Label(i), PublicKey: X, Trustee: i})
+		case "Pedersen":
+			for j, k := range t.Pedersen.VerificationKeys {
+				vk, err := pedersenVerificationKey(*t.Pedersen, j+1, elec)
+				if err != nil {
+					return nil, fmt.Errorf(" Pedersen trustees %d\n  %s\n", i+1, err)
+				}
+				keys = append(keys, decryptionKey{Name: k.Label(len(keys)), PublicKey: vk, Trustee: i, Index: j + 1})
+			}
+		}
+	}
+	return keys, nil
+}
+
+// Key index for each partial decryption,
+// check every Single trustee and at least threshold Pedersen trustees took part
+func partialOwners(res Result, keys []decryptionKey, trustees []Trustee) ([]int, error) {
+	var owners []int
+	seen := make(map[int]bool)
+	for ip, partial := range res.PartialDecryptions {
+		owner := partial.Owner
+		if owner == 0 {
+			owner = ip + 1
+		}
+		if owner < 1 || owner > len(keys) {
+			return nil, fmt.Errorf(" Partial decryption %d from unknown trustee %d\n", ip+1, owner)
+		}
+		if seen[owner] {
+			return nil, fmt.Errorf(" Many partial decryptions from trustee %s\n", keys[owner-1].Name)
+		}
+		seen[owner] = true
+		owners = append(owners, owner-1)
+	}
+
+	for i, t := range trustees {
+		count := 0
+		for ik, k := range keys {
+			if k.Trustee == i && seen[ik+1] {
+				count++
+			}
+		}
+		switch t.Kind {
+		case "Single":
+			if count != 1 {
+				return nil, fmt.Errorf(" Missing partial decryption from trustee %s\n", t.Single.Label(i))
+			}
+		case "Pedersen":
+			if count < t.Pedersen.Threshold {
+				return nil, fmt.Errorf(" %d partial decryptions for Pedersen trustees %d\n  threshold %d\n", count, i+1, t.Pedersen.Threshold)
+			}
+		}
+	}
+	return owners, nil
+}
+
+// Lagrange coefficient at 0 for Pedersen trustee j with participating trustees indexes
+//
+//	lambda_j = product for k != j of k / (k - j) (mod q)
+func lagrange(j int, indexes []int, q *big.Int) *big.Int {
+	l := big.NewInt(1)
+	for _, k := range indexes {
+		if k == j {
+			continue
+		}
+		kj := new(big.Int).Mod(big.NewInt(int64(k-j)), q)
+		l = l.Mul(l, big.NewInt(int64(k))).Mod(l, q)
+		l = l.Mul(l, new(big.Int).ModInverse(kj, q)).Mod(l, q)
+	}
+	return l
+}
+
+// Combine decryption factors of participating trustees
+//
+//	F = product of Single factors x product of Pedersen factors**lambda
+func combineFactors(elec Election, res Result, trustees []Trustee) ([][]Element, error) {
+	grp := electionGroup(elec)
+	q := grp.Q()
+
+	keys, err := decryptionKeys(trustees, elec)
+	if err != nil {
+		return nil, err
+	}
+	owners, err := partialOwners(res, keys, trustees)
+	if err != nil {
+		return nil, err
+	}
+
+	// Participating trustees indexes for each Pedersen trustees
+	indexes := make(map[int][]int)
+	for _, o := range owners {
+		if keys[o].Index != 0 {
+			indexes[keys[o].Trustee] = append(indexes[keys[o].Trustee], keys[o].Index)
+		}
+	}
+
+	var F [][]Element
+	for i, question := range res.EncryptedTally {
+		var factors []Element
+		for j := range question {
+			Fj := grp.One()
+			for ip, partial := range res.PartialDecryptions {
+				if len(partial.DecryptionFactors) <= i || len(partial.DecryptionFactors[i]) <= j {
+					return nil, fmt.Errorf(" Partial decryption of trustee %s\n  not matching encrypted tally\n", keys[owners[ip]].Name)
+				}
+				key := keys[owners[ip]]
+				f, err := grp.Parse(partial.DecryptionFactors[i][j])
+				if err != nil {
+					return nil, questionErrorf(i, " Bad decryption factor of trustee %s\n  question %d\n", key.Name, i+1)
+				}
+				if key.Index != 0 {
+					f = grp.Exp(f, lagrange(key.Index, indexes[key.Trustee], q))
+				}
+				Fj = grp.Mul(Fj, f)
+			}
+			factors = append(factors, Fj)
+		}
+		F = append(F, factors)
+	}
+	return F, nil
+}
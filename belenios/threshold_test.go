@@ -0,0 +1,243 @@
+package belenios
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Test group with prover side
+type testGroup struct {
+	Group
+}
+
+func newTestGroup(elec Election) testGroup {
+	return testGroup{electionGroup(elec)}
+}
+
+func (tg testGroup) random() *big.Int {
+	r, _ := rand.Int(rand.Reader, tg.Q())
+	return r
+}
+
+// Proof with A = g**w, response = w + x*challenge
+func (tg testGroup) prove(x *big.Int, prefix string, bases ...Element) Proof {
+	w := tg.random()
+	var commitments []string
+	for _, b := range bases {
+		commitments = append(commitments, tg.Exp(b, w).String())
+	}
+	c := hashQ(prefix+strings.Join(commitments, ","), tg.Q())
+	r := new(big.Int).Mul(x, c)
+	r = r.Add(r, w).Mod(r, tg.Q())
+	return Proof{Challenge: c.String(), Response: r.String()}
+}
+
+// Signature with A = g**w, response = w - sk*challenge
+func (tg testGroup) sign(sk *big.Int, msg string) SignedMsg {
+	w := tg.random()
+	A := tg.Exp(tg.G(), w)
+	c := hashQ(fmt.Sprintf("sigmsg|%s|%s", msg, A), tg.Q())
+	r := new(big.Int).Mul(sk, c)
+	r = r.Sub(w, r).Mod(r, tg.Q())
+	return SignedMsg{Message: msg, Signature: Proof{Challenge: c.String(), Response: r.String()}}
+}
+
+func (tg testGroup) publicKey(x *big.Int) TrusteePublicKey {
+	X := tg.Exp(tg.G(), x)
+	return TrusteePublicKey{
+		Pok:       tg.prove(x, fmt.Sprintf("pok|%s|", X), tg.G()),
+		PublicKey: X.String(),
+	}
+}
+
+// t-of-n Pedersen trustees, return trustees and their shares
+func newTestPedersen(tg testGroup, n, t int) (Pedersen, []*big.Int) {
+	p := Pedersen{Threshold: t}
+	shares := make([]*big.Int, n)
+	for j := range shares {
+		shares[j] = big.NewInt(0)
+	}
+	for i := 0; i < n; i++ {
+		sk := tg.random()
+		cert, _ := json.Marshal(CertKeys{
+			Verification: tg.Exp(tg.G(), sk).String(),
+			Encryption:   tg.Exp(tg.G(), tg.random()).String(),
+		})
+		p.Certs = append(p.Certs, tg.sign(sk, string(cert)))
+
+		var coefs Coefexps
+		var poly []*big.Int
+		for k := 0; k < t; k++ {
+			a := tg.random()
+			poly = append(poly, a)
+			coefs.Coefexps = append(coefs.Coefexps, tg.Exp(tg.G(), a).String())
+		}
+		msg, _ := json.Marshal(coefs)
+		p.Coefexps = append(p.Coefexps, tg.sign(sk, string(msg)))
+
+		// shares s_j += f_i(j)
+		for j := range shares {
+			fj := big.NewInt(0)
+			for k := t - 1; k >= 0; k-- {
+				fj = fj.Mul(fj, big.NewInt(int64(j+1))).Add(fj, poly[k]).Mod(fj, tg.Q())
+			}
+			shares[j] = shares[j].Add(shares[j], fj).Mod(shares[j], tg.Q())
+		}
+	}
+	for _, s := range shares {
+		p.VerificationKeys = append(p.VerificationKeys, tg.publicKey(s))
+	}
+	return p, shares
+}
+
+// Encrypted tally for results and partial decryptions by trustees with shares
+func newTestTally(tg testGroup, y Element, results [][]int, owners []int, shares []*big.Int) Result {
+	var res Result
+	res.Result = results
+	res.NumTallied = 3
+	for _, o := range owners {
+		res.PartialDecryptions = append(res.PartialDecryptions, PartialDecryption{Owner: o})
+	}
+	for i, question := range results {
+		res.EncryptedTally = append(res.EncryptedTally, nil)
+		for ip := range res.PartialDecryptions {
+			res.PartialDecryptions[ip].DecryptionFactors = append(res.PartialDecryptions[ip].DecryptionFactors, nil)
+			res.PartialDecryptions[ip].DecryptionProofs = append(res.PartialDecryptions[ip].DecryptionProofs, nil)
+		}
+		for _, m := range question {
+			r := tg.random()
+			alpha := tg.Exp(tg.G(), r)
+			beta := tg.Mul(tg.Exp(y, r), tg.Exp(tg.G(), big.NewInt(int64(m))))
+			res.EncryptedTally[i] = append(res.EncryptedTally[i], struct {
+				Alpha string `json:"alpha"`
+				Beta  string `json:"beta"`
+			}{alpha.String(), beta.String()})
+
+			for ip, o := range owners {
+				x := shares[o-1]
+				X := tg.Exp(tg.G(), x)
+				f := tg.Exp(alpha, x)
+				pd := &res.PartialDecryptions[ip]
+				pd.DecryptionFactors[i] = append(pd.DecryptionFactors[i], f.String())
+				pd.DecryptionProofs[i] = append(pd.DecryptionProofs[i], tg.prove(x, fmt.Sprintf("decrypt|%s|", X), tg.G(), alpha))
+			}
+		}
+	}
+	return res
+}
+
+// Encrypted count from encrypted tally
+func countFromTally(grp Group, res Result) [][]Choice {
+	var count [][]Choice
+	for _, question := range res.EncryptedTally {
+		var choices []Choice
+		for _, c := range question {
+			alpha, _ := grp.Parse(c.Alpha)
+			beta, _ := grp.Parse(c.Beta)
+			choices = append(choices, Choice{Alpha: alpha, Beta: beta})
+		}
+		count = append(count, choices)
+	}
+	return count
+}
+
+func TestThresholdDecryption(t *testing.T) {
+	elec, dataRes, _, _ := readTestData(t)
+	testThresholdDecryption(t, elec, dataRes)
+
+	elec.PublicKey.Group = GroupParams{Name: "Ed25519"}
+	testThresholdDecryption(t, elec, dataRes)
+}
+
+func testThresholdDecryption(t *testing.T, elec Election, dataRes Result) {
+	tg := newTestGroup(elec)
+
+	// 2-of-3 Pedersen trustees
+	pedersen, shares := newTestPedersen(tg, 3, 2)
+	trustees := []Trustee{{Kind: "Pedersen", Pedersen: &pedersen}}
+	assert.Equal(t, nil, validateTrustees(trustees), "validateTrustees")
+
+	assert.Equal(t, nil, verifyTrusteesPedersen(trustees, elec), "verifyTrusteesPedersen")
+
+	keys, err := decryptionKeys(trustees, elec)
+	assert.Equal(t, nil, err, "decryptionKeys")
+	for j, k := range keys {
+		assert.Equal(t, pedersen.VerificationKeys[j].PublicKey, k.PublicKey.String(), "Verification key from coefexps")
+	}
+
+	secret := big.NewInt(0)
+	for i, s := range shares[:2] {
+		l := lagrange(i+1, []int{1, 2}, tg.Q())
+		secret = secret.Add(secret, l.Mul(l, s)).Mod(secret, tg.Q())
+	}
+	y := tg.Exp(tg.G(), secret)
+	elec.PublicKey.Y = y.String()
+	assert.Equal(t, nil, verifyElectionPublicKey(trustees, elec), "Pedersen election public key")
+
+	// Decryption by trustees 1 and 3
+	res := newTestTally(tg, y, dataRes.Result, []int{1, 3}, shares)
+	j, _ := json.Marshal(res)
+	var jres Result
+	json.Unmarshal(j, &jres)
+	assert.Equal(t, 3, jres.PartialDecryptions[1].Owner, "Owned partial decryption")
+	assert.Equal(t, res.PartialDecryptions, jres.PartialDecryptions, "Read owned partial decryptions")
+
+	assert.Equal(t, nil, verifyDecryptionFactors(elec, res, trustees), "verifyDecryptionFactors")
+	results, err := DecryptResults(elec, res, countFromTally(tg, res), trustees, res.NumTallied)
+	assert.Equal(t, nil, err, "DecryptResults")
+	assert.Equal(t, dataRes.Result, results, "Threshold decrypted results")
+
+	// Not enough trustees
+	one := res
+	one.PartialDecryptions = res.PartialDecryptions[:1]
+	err = verifyDecryptionFactors(elec, one, trustees)
+	assert.Contains(t, err.Error(), "1 partial decryptions for Pedersen trustees 1\n  threshold 2", "Threshold")
+	_, err = DecryptResults(elec, one, countFromTally(tg, res), trustees, res.NumTallied)
+	assert.NotEqual(t, nil, err, "DecryptResults under threshold")
+
+	// Factor from a wrong share
+	res.PartialDecryptions[1].Owner = 2
+	err = verifyDecryptionFactors(elec, res, trustees)
+	assert.Contains(t, err.Error(), "Decryption proof of trustee #2\n  question 1, answer blank", "Wrong owner")
+}
+
+func TestPedersenSetup(t *testing.T) {
+	elec, _, _, _ := readTestData(t)
+	tg := newTestGroup(elec)
+
+	pedersen, _ := newTestPedersen(tg, 3, 2)
+	trustees := []Trustee{{Kind: "Pedersen", Pedersen: &pedersen}}
+	assert.Equal(t, nil, verifyTrusteesPedersen(trustees, elec), "verifyTrusteesPedersen")
+
+	// Bad cert signature
+	cert := pedersen.Certs[1]
+	pedersen.Certs[1].Message = pedersen.Certs[0].Message
+	err := verifyTrusteesPedersen(trustees, elec)
+	assert.Contains(t, err.Error(), "cert 2: signature KO", "Bad cert")
+	pedersen.Certs[1] = cert
+
+	// Coefexps signed by an other trustee
+	coefexps := pedersen.Coefexps[2]
+	pedersen.Coefexps[2] = pedersen.Coefexps[0]
+	err = verifyTrusteesPedersen(trustees, elec)
+	assert.Contains(t, err.Error(), "coefexps 3: signature KO", "Bad coefexps signature")
+	pedersen.Coefexps[2] = coefexps
+
+	// Verification key not matching coefexps
+	vk := pedersen.VerificationKeys[0]
+	pedersen.VerificationKeys[0] = tg.publicKey(tg.random())
+	err = verifyTrusteesPedersen(trustees, elec)
+	assert.Contains(t, err.Error(), "verification key #1: not matching coefexps", "Bad verification key")
+	pedersen.VerificationKeys[0] = vk
+
+	// Bad proof of knowledge
+	pedersen.VerificationKeys[1].Pok = pedersen.VerificationKeys[0].Pok
+	err = verifyTrusteesPedersen(trustees, elec)
+	assert.Contains(t, err.Error(), "verification key #2: proof of knowledge KO", "Bad verification key pok")
+}
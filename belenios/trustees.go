@@ -0,0 +1,329 @@
+package belenios
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+)
+
+// Read tagged trustees.json entry
+//
+//	["Single",{"pok":{...},"public_key":"...","name":"..."}]
+//	["Pedersen",{"threshold":t,"certs":[...],"coefexps":[...],"verification_keys":[...]}]
+func (t *Trustee) UnmarshalJSON(data []byte) error {
+	var entry []json.RawMessage
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return err
+	}
+	if len(entry) != 2 {
+		return fmt.Errorf("trustee entry with %d fields", len(entry))
+	}
+	if err := json.Unmarshal(entry[0], &t.Kind); err != nil {
+		return err
+	}
+	switch t.Kind {
+	case "Single":
+		t.Single = new(TrusteePublicKey)
+		return json.Unmarshal(entry[1], t.Single)
+	case "Pedersen":
+		t.Pedersen = new(Pedersen)
+		return json.Unmarshal(entry[1], t.Pedersen)
+	}
+	return fmt.Errorf("unknown trustee kind %q", t.Kind)
+}
+
+func (t Trustee) MarshalJSON() ([]byte, error) {
+	switch t.Kind {
+	case "Single":
+		return json.Marshal([]interface{}{t.Kind, t.Single})
+	case "Pedersen":
+		return json.Marshal([]interface{}{t.Kind, t.Pedersen})
+	}
+	return nil, fmt.Errorf("unknown trustee kind %q", t.Kind)
+}
+
+// Trustee name for messages, i is the trustee index
+func (k TrusteePublicKey) Label(i int) string {
+	if k.Name != "" {
+		return k.Name
+	}
+	return fmt.Sprintf("#%d", i+1)
+}
+
+// Read trustees.json
+func ReadTrustees(byteValue []byte) ([]Trustee, error) {
+	var trustees []Trustee
+	err := json.Unmarshal(byteValue, &trustees)
+	return trustees, err
+}
+
+// Check the shape of trustees entries
+func validateTrustees(trustees []Trustee) error {
+	if len(trustees) == 0 {
+		return fmt.Errorf(" No trustee\n")
+	}
+	for i, t := range trustees {
+		switch t.Kind {
+		case "Single":
+			if err := validateTrusteePublicKey(*t.Single); err != nil {
+				return fmt.Errorf(" Single trustee %s\n  %s\n", t.Single.Label(i), err)
+			}
+		case "Pedersen":
+			if err := validatePedersen(*t.Pedersen); err != nil {
+				return fmt.Errorf(" Pedersen trustees %d\n  %s\n", i+1, err)
+			}
+		}
+	}
+	return nil
+}
+
+func validateTrusteePublicKey(k TrusteePublicKey) error {
+	if k.PublicKey == "" {
+		return fmt.Errorf("missing public_key")
+	}
+	if k.Pok.Challenge == "" || k.Pok.Response == "" {
+		return fmt.Errorf("missing pok")
+	}
+	return nil
+}
+
+func validatePedersen(p Pedersen) error {
+	n := len(p.VerificationKeys)
+	if n == 0 {
+		return fmt.Errorf("no verification_keys")
+	}
+	if p.Threshold < 1 || p.Threshold > n {
+		return fmt.Errorf("threshold %d not in [1, %d]", p.Threshold, n)
+	}
+	if len(p.Certs) != n {
+		return fmt.Errorf("%d certs for %d trustees", len(p.Certs), n)
+	}
+	if len(p.Coefexps) != n {
+		return fmt.Errorf("%d coefexps for %d trustees", len(p.Coefexps), n)
+	}
+	for i, k := range p.VerificationKeys {
+		if k.PublicKey == "" {
+			return fmt.Errorf("verification key %d: missing public_key", i+1)
+		}
+	}
+	for i, c := range p.Certs {
+		var keys CertKeys
+		if err := json.Unmarshal([]byte(c.Message), &keys); err != nil {
+			return fmt.Errorf("cert %d: %s", i+1, err)
+		}
+		if keys.Verification == "" || keys.Encryption == "" {
+			return fmt.Errorf("cert %d: missing key", i+1)
+		}
+	}
+	for i, c := range p.Coefexps {
+		var coefs Coefexps
+		if err := json.Unmarshal([]byte(c.Message), &coefs); err != nil {
+			return fmt.Errorf("coefexps %d: %s", i+1, err)
+		}
+		if len(coefs.Coefexps) != p.Threshold {
+			return fmt.Errorf("coefexps %d: %d coefexps for threshold %d", i+1, len(coefs.Coefexps), p.Threshold)
+		}
+	}
+	return nil
+}
+
+// Verify proof of knowledge of a trustee private key
+func verifyTrusteePok(k TrusteePublicKey, elec Election) bool {
+	grp := electionGroup(elec)
+	g, q := grp.G(), grp.Q()
+
+	X, err := grp.Parse(k.PublicKey)
+	if err != nil {
+		return false
+	}
+	r, _ := new(big.Int).SetString(k.Pok.Response, 10)
+	c, ok := new(big.Int).SetString(k.Pok.Challenge, 10)
+	if r == nil || !ok {
+		return false
+	}
+
+	// Trustee public key proof of knowledge
+	// A = g**response / public_key**challenge
+	A := grp.Mul(grp.Exp(g, r), grp.Inv(grp.Exp(X, c)))
+
+	// SUM256("pok|public_key|A") mod q
+	HString := fmt.Sprintf("pok|%s|%s", X, A)
+	left := hashQ(HString, q)
+
+	return left.Cmp(c) == 0
+}
+
+// Verify proofs of knowledge of Single trustees
+func verifyTrusteesPoks(trustees []Trustee, elec Election) error {
+	for i, t := range trustees {
+		if t.Kind != "Single" {
+			continue
+		}
+		if !verifyTrusteePok(*t.Single, elec) {
+			return fmt.Errorf(" Proof of knowledge of trustee %s\n  KO !!!!!\n", t.Single.Label(i))
+		}
+	}
+	return nil // no error
+}
+
+// Verify election public key
+//
+//	y = product of Single trustees public keys
+//	    x product of Pedersen constant coefexps
+func verifyElectionPublicKey(trustees []Trustee, elec Election) error {
+	grp := electionGroup(elec)
+	y, _ := grp.Parse(elec.PublicKey.Y)
+
+	Y := grp.One()
+	for i, t := range trustees {
+		switch t.Kind {
+		case "Single":
+			X, err := grp.Parse(t.Single.PublicKey)
+			if err != nil {
+				return fmt.Errorf(" Bad public key for trustee %s\n", t.Single.Label(i))
+			}
+			Y = grp.Mul(Y, X)
+		case "Pedersen":
+			for j, c := range t.Pedersen.Coefexps {
+				var coefs Coefexps
+				json.Unmarshal([]byte(c.Message), &coefs)
+				if len(coefs.Coefexps) == 0 {
+					return fmt.Errorf(" Missing coefexps %d for Pedersen trustees %d\n", j+1, i+1)
+				}
+				X, err := grp.Parse(coefs.Coefexps[0])
+				if err != nil {
+					return fmt.Errorf(" Bad coefexps %d for Pedersen trustees %d\n", j+1, i+1)
+				}
+				Y = grp.Mul(Y, X)
+			}
+		}
+	}
+
+	if y == nil || !grp.Equal(Y, y) {
+		return fmt.Errorf(" Election public key\n  %s\n  is not the trustees key\n  %s\n", elec.PublicKey.Y, Y)
+	}
+	return nil // no error
+}
+
+// Verify signed message with verification key
+//
+//	A = g**response x verification_key**challenge
+//	SUM256("sigmsg|message|A") mod q
+func verifySignedMsg(m SignedMsg, vk Element, elec Election) bool {
+	grp := electionGroup(elec)
+	g, q := grp.G(), grp.Q()
+
+	r, okr := new(big.Int).SetString(m.Signature.Response, 10)
+	c, okc := new(big.Int).SetString(m.Signature.Challenge, 10)
+	if !okr || !okc || vk == nil {
+		return false
+	}
+	A := grp.Mul(grp.Exp(g, r), grp.Exp(vk, c))
+
+	HString := fmt.Sprintf("sigmsg|%s|%s", m.Message, A)
+	left := hashQ(HString, q)
+
+	return left.Cmp(c) == 0
+}
+
+// Verify Pedersen trustees setup
+func verifyPedersen(p Pedersen, elec Election) error {
+	grp := electionGroup(elec)
+
+	// Certs: channel keys signed with verification key
+	var vks []Element
+	for i, c := range p.Certs {
+		var keys CertKeys
+		json.Unmarshal([]byte(c.Message), &keys)
+		vk, errv := grp.Parse(keys.Verification)
+		ek, erre := grp.Parse(keys.Encryption)
+		if errv != nil || erre != nil || !grp.IsMember(vk) || !grp.IsMember(ek) {
+			return fmt.Errorf("cert %d: key not in group", i+1)
+		}
+		if !verifySignedMsg(c, vk, elec) {
+			return fmt.Errorf("cert %d: signature KO !!!!!", i+1)
+		}
+		vks = append(vks, vk)
+	}
+
+	// Coefexps: signed by cert owner, in group
+	for i, c := range p.Coefexps {
+		if !verifySignedMsg(c, vks[i], elec) {
+			return fmt.Errorf("coefexps %d: signature KO !!!!!", i+1)
+		}
+		var coefs Coefexps
+		json.Unmarshal([]byte(c.Message), &coefs)
+		for k, sc := range coefs.Coefexps {
+			C, err := grp.Parse(sc)
+			if err != nil || !grp.IsMember(C) {
+				return fmt.Errorf("coefexps %d: coefexp %d not in group", i+1, k+1)
+			}
+		}
+	}
+
+	// Verification keys: computed from coefexps, with proof of knowledge
+	for j, k := range p.VerificationKeys {
+		vk, err := pedersenVerificationKey(p, j+1, elec)
+		if err != nil {
+			return err
+		}
+		if vk.String() != k.PublicKey {
+			return fmt.Errorf("verification key %s: not matching coefexps", k.Label(j))
+		}
+		if !verifyTrusteePok(k, elec) {
+			return fmt.Errorf("verification key %s: proof of knowledge KO !!!!!", k.Label(j))
+		}
+	}
+	return nil // no error
+}
+
+// Verify setup of Pedersen trustees
+func verifyTrusteesPedersen(trustees []Trustee, elec Election) error {
+	for i, t := range trustees {
+		if t.Kind != "Pedersen" {
+			continue
+		}
+		if err := verifyPedersen(*t.Pedersen, elec); err != nil {
+			return fmt.Errorf(" Pedersen trustees %d\n  %s\n", i+1, err)
+		}
+	}
+	return nil // no error
+}
+
+// Election public key and trustees keys in group, proofs in [0, q)
+func verifyTrusteesGroupMembership(trustees []Trustee, elec Election) error {
+	grp := electionGroup(elec)
+
+	msg, ok := checkGroupAndRange(grp, []string{elec.PublicKey.Y}, nil)
+	if !ok {
+		return fmt.Errorf(" Election public key\n  %s\n", msg)
+	}
+
+	for i, t := range trustees {
+		switch t.Kind {
+		case "Single":
+			msg, ok := checkGroupAndRange(grp, []string{t.Single.PublicKey}, []Proof{t.Single.Pok})
+			if !ok {
+				return fmt.Errorf(" Trustee %s\n  %s\n", t.Single.Label(i), msg)
+			}
+		case "Pedersen":
+			var proofs []Proof
+			for _, c := range t.Pedersen.Certs {
+				proofs = append(proofs, c.Signature)
+			}
+			for _, c := range t.Pedersen.Coefexps {
+				proofs = append(proofs, c.Signature)
+			}
+			var elements []string
+			for _, k := range t.Pedersen.VerificationKeys {
+				elements = append(elements, k.PublicKey)
+				proofs = append(proofs, k.Pok)
+			}
+			msg, ok := checkGroupAndRange(grp, elements, proofs)
+			if !ok {
+				return fmt.Errorf(" Pedersen trustees %d\n  %s\n", i+1, msg)
+			}
+		}
+	}
+	return nil // no error
+}
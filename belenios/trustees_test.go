@@ -0,0 +1,68 @@
+package belenios
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadTrustees(t *testing.T) {
+	elec, _, _, trustees := readTestData(t)
+
+	assert.Equal(t, 1, len(trustees), "1 trustee")
+	assert.Equal(t, "Single", trustees[0].Kind, "Single trustee")
+	assert.Equal(t, "server", trustees[0].Single.Name, "Trustee name")
+	assert.Equal(t, nil, validateTrustees(trustees), "validateTrustees")
+	assert.Equal(t, nil, verifyTrusteesPoks(trustees, elec), "verifyTrusteesPoks")
+
+	assert.Equal(t, nil, verifyElectionPublicKey(trustees, elec), "verifyElectionPublicKey")
+	assert.Equal(t, nil, verifyTrusteesGroupMembership(trustees, elec), "verifyTrusteesGroupMembership")
+
+	// Tampered trustee public key
+	pk := trustees[0].Single.PublicKey
+	trustees[0].Single.PublicKey = elec.PublicKey.Group.G
+	err := verifyTrusteesPoks(trustees, elec)
+	assert.Contains(t, err.Error(), "trustee server", "Bad proof of knowledge")
+	err = verifyElectionPublicKey(trustees, elec)
+	assert.Contains(t, err.Error(), "is not the trustees key", "Bad election public key")
+	trustees[0].Single.PublicKey = elec.PublicKey.Group.P
+	err = verifyTrusteesGroupMembership(trustees, elec)
+	assert.Contains(t, err.Error(), "Trustee server\n  element", "Trustee key not in group")
+	trustees[0].Single.PublicKey = pk
+
+	// Marshal back to tagged entries
+	j, err := json.Marshal(trustees)
+	assert.Equal(t, nil, err, "Marshal trustees")
+	again, err := ReadTrustees(j)
+	assert.Equal(t, nil, err, "Read marshaled trustees")
+	assert.Equal(t, trustees, again, "Same trustees")
+
+	_, err = ReadTrustees([]byte(`[["Unknown",{}]]`))
+	assert.NotEqual(t, nil, err, "Unknown trustee kind")
+
+	pedersen := `[["Pedersen",{"threshold":3,
+		"certs":[{"message":"{\"verification\":\"2\",\"encryption\":\"3\"}","signature":{"challenge":"1","response":"1"}},
+			{"message":"{\"verification\":\"4\",\"encryption\":\"5\"}","signature":{"challenge":"1","response":"1"}}],
+		"coefexps":[{"message":"{\"coefexps\":[\"2\",\"3\"]}","signature":{"challenge":"1","response":"1"}},
+			{"message":"{\"coefexps\":[\"4\",\"5\"]}","signature":{"challenge":"1","response":"1"}}],
+		"verification_keys":[{"pok":{"challenge":"1","response":"1"},"public_key":"2"},
+			{"pok":{"challenge":"1","response":"1"},"public_key":"3"}]}]]`
+	trustees, err = ReadTrustees([]byte(pedersen))
+	assert.Equal(t, nil, err, "Read Pedersen trustees")
+	assert.Equal(t, "Pedersen", trustees[0].Kind, "Pedersen trustee")
+	assert.Equal(t, 2, len(trustees[0].Pedersen.Certs), "2 certs")
+	err = validateTrustees(trustees)
+	assert.Contains(t, err.Error(), "threshold 3 not in [1, 2]", "Bad threshold")
+
+	trustees[0].Pedersen.Threshold = 2
+	assert.Equal(t, nil, validateTrustees(trustees), "Valid Pedersen trustees")
+
+	// y = 2 x 4 (constant coefexps)
+	elec.PublicKey.Y = "8"
+	assert.Equal(t, nil, verifyElectionPublicKey(trustees, elec), "Pedersen election public key")
+
+	trustees[0].Pedersen.Coefexps[1].Message = `{"coefexps":["4"]}`
+	err = validateTrustees(trustees)
+	assert.Contains(t, err.Error(), "coefexps 2: 1 coefexps for threshold 2", "Bad coefexps")
+}
@@ -0,0 +1,312 @@
+// Package belenios verifies Belenios elections, following the
+// Belenios specification (https://www.belenios.org/specification.pdf)
+package belenios
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Verifier of a Belenios election
+//
+// Credentials is nil when public credentials are not available,
+// Weights is empty without weighted credentials.
+// With All, verification goes on after failures, only
+// well verified ballots are counted.
+type Verifier struct {
+	Election    Election
+	Trustees    []Trustee
+	Credentials []string
+	Weights     map[string]int
+	Revote      bool   // count last ballot of each credential
+	All         bool   // verify all ballots and checks
+	Workers     int    // parallel ballot verifications, 1 when 0
+	Progress    func() // called after each ballot verification
+
+	credSet map[string]bool
+}
+
+// Named verification, Err is nil when verified
+type Check struct {
+	Name string
+	Err  error
+}
+
+// Verifications of a ballot
+type BallotCheck struct {
+	Index   int // ballot number in ballots.jsons
+	Tracker string
+	Checks  []Check
+}
+
+// First failed check of the ballot
+func (b BallotCheck) Err() error {
+	return firstErr(b.Checks)
+}
+
+// Structured result of an election verification,
+// checks stop at the first failure unless Verifier.All
+type Report struct {
+	Fingerprint       string
+	Setup             []Check
+	Ballots           []BallotCheck
+	CredentialsUsed   int
+	CredentialsUnused int
+	Duplicates        []Duplicate
+	Counted           int // ballots counted after revote
+	TotalWeight       int // of counted ballots, 0 without weights
+	Tally             []Check
+	EncryptedTally    [][]Ciphertext // recomputed from counted ballots
+	Results           [][]int        // decrypted homomorphic results
+}
+
+// Failed check, Ballot is 0 for setup and tally checks
+type Failure struct {
+	Ballot   int
+	Tracker  string
+	Question int // from 1, 0 when not about a question
+	Check    string
+	Err      error
+}
+
+// All failed checks in verification order
+func (r Report) Failures() []Failure {
+	var failures []Failure
+	add := func(index int, tracker string, checks []Check) {
+		for _, c := range checks {
+			if c.Err == nil {
+				continue
+			}
+			f := Failure{Ballot: index, Tracker: tracker, Check: c.Name, Err: c.Err}
+			var qe QuestionError
+			if errors.As(c.Err, &qe) {
+				f.Question = qe.Question
+			}
+			failures = append(failures, f)
+		}
+	}
+	add(0, "", r.Setup)
+	for _, b := range r.Ballots {
+		add(b.Index, b.Tracker, b.Checks)
+	}
+	add(0, "", r.Tally)
+	return failures
+}
+
+// First failure, nil when all checks are verified
+func (r Report) Err() error {
+	failures := r.Failures()
+	if len(failures) == 0 {
+		return nil
+	}
+	f := failures[0]
+	if f.Ballot > 0 {
+		return fmt.Errorf(" Ballot %s\n%s", f.Tracker, f.Err)
+	}
+	return f.Err
+}
+
+// Verification step, next steps are meaningless when a required step fails
+type step struct {
+	name     string
+	required bool
+	verify   func() error
+}
+
+// Run steps until the first failure, with all only a required step stops
+func runSteps(steps []step, all bool) []Check {
+	var checks []Check
+	for _, s := range steps {
+		err := s.verify()
+		checks = append(checks, Check{Name: s.name, Err: err})
+		if err != nil && (!all || s.required) {
+			break
+		}
+	}
+	return checks
+}
+
+func firstErr(checks []Check) error {
+	for _, c := range checks {
+		if c.Err != nil {
+			return c.Err
+		}
+	}
+	return nil
+}
+
+// A failure stops verification
+func (v *Verifier) stop(checks []Check, steps []step) bool {
+	if v.All {
+		return len(checks) < len(steps) // required step failed
+	}
+	return firstErr(checks) != nil
+}
+
+func (v *Verifier) setupSteps() []step {
+	return []step{
+		{"Election group", true, func() error { return VerifyGroup(v.Election) }},
+		{"Trustees", true, func() error { return validateTrustees(v.Trustees) }},
+		{"Group membership", true, func() error { return verifyTrusteesGroupMembership(v.Trustees, v.Election) }},
+		{"Election public key", false, func() error { return verifyElectionPublicKey(v.Trustees, v.Election) }},
+		{"Trustees proofs of knowledge", false, func() error { return verifyTrusteesPoks(v.Trustees, v.Election) }},
+		{"Pedersen trustees certs and keys", false, func() error { return verifyTrusteesPedersen(v.Trustees, v.Election) }},
+	}
+}
+
+// Verify trustees and election public key
+func (v *Verifier) VerifySetup() []Check {
+	return runSteps(v.setupSteps(), v.All)
+}
+
+func (v *Verifier) ballotSteps(b Ballot) []step {
+	steps := ballotSteps(b, v.Election, Fingerprint(v.Election))
+	if v.Credentials != nil {
+		steps = append(steps, step{"Credential", false, func() error { return verifyBallotCredential(b, v.credentialsSet()) }})
+	}
+	return steps
+}
+
+// Verify ballot proofs and credential
+func (v *Verifier) VerifyBallot(b Ballot) error {
+	return firstErr(runSteps(v.ballotSteps(b), false))
+}
+
+func (v *Verifier) credentialsSet() map[string]bool {
+	if v.credSet == nil {
+		v.credSet = credentialsSet(v.Credentials)
+	}
+	return v.credSet
+}
+
+// Verify ballots with Workers goroutines, done is called in ballots
+// order and stops verifications when it returns false
+func (v *Verifier) verifyBallots(ballots []Ballot, done func(i int, checks []Check) bool) {
+	workers := v.Workers
+	if workers < 1 {
+		workers = 1
+	}
+	if v.Credentials != nil {
+		v.credentialsSet() // built before sharing between workers
+	}
+
+	results := make([]chan []Check, len(ballots))
+	for i := range results {
+		results[i] = make(chan []Check, 1)
+	}
+	jobs := make(chan int)
+	stop := make(chan struct{})
+	defer close(stop)
+	go func() {
+		defer close(jobs)
+		for i := range ballots {
+			select {
+			case jobs <- i:
+			case <-stop:
+				return
+			}
+		}
+	}()
+	for w := 0; w < workers; w++ {
+		go func() {
+			for i := range jobs {
+				results[i] <- runSteps(v.ballotSteps(ballots[i]), v.All)
+			}
+		}()
+	}
+
+	for i := range ballots {
+		if !done(i, <-results[i]) {
+			return
+		}
+	}
+}
+
+// Verify setup, ballots and result of an election
+func (v *Verifier) Verify(ballots []Ballot, res Result) Report {
+	r := Report{Fingerprint: Fingerprint(v.Election)}
+
+	steps := v.setupSteps()
+	r.Setup = runSteps(steps, v.All)
+	if v.stop(r.Setup, steps) {
+		return r
+	}
+
+	var valid []Ballot
+	var validIndex []int // ballot numbers of valid ballots
+	var invalid []string // trackers of ballots not counted
+	v.verifyBallots(ballots, func(i int, checks []Check) bool {
+		b := ballots[i]
+		r.Ballots = append(r.Ballots, BallotCheck{Index: i + 1, Tracker: b.Tracker, Checks: checks})
+		if v.Progress != nil {
+			v.Progress()
+		}
+		if firstErr(checks) != nil {
+			invalid = append(invalid, b.Tracker)
+			return v.All
+		}
+		valid = append(valid, b)
+		validIndex = append(validIndex, i+1)
+		return true
+	})
+	if len(valid) < len(ballots) && !v.All {
+		return r // stopped at first bad ballot
+	}
+	if v.Credentials != nil {
+		r.CredentialsUsed, r.CredentialsUnused = credentialsUsage(valid, v.credentialsSet())
+	}
+
+	// Revote: last ballot counts
+	counted, duplicates := lastBallots(valid, validIndex)
+	r.Duplicates, r.Counted = duplicates, len(counted)
+	if len(duplicates) > 0 && !v.Revote {
+		err := fmt.Errorf(" %d ballots with duplicate credentials\n", len(duplicates))
+		r.Tally = append(r.Tally, Check{Name: "Duplicate credentials", Err: err})
+		if !v.All {
+			return r
+		}
+	}
+
+	var results [][]int
+	steps = []step{
+		{"Tally group membership", true, func() error { return verifyTallyGroupMembership(v.Election, res) }},
+		{"Number of tallied ballots", false, func() error { return verifyNumTallied(res, counted, invalid, duplicates) }},
+	}
+	if len(v.Weights) > 0 {
+		r.TotalWeight = TotalWeight(counted, v.Weights)
+		steps = append(steps, step{"Total weight", false, func() error { return verifyTotalWeight(res, r.TotalWeight) }})
+	}
+	if HasNonHomomorphic(v.Election) {
+		steps = append(steps, step{"Mixnet shuffles", false, func() error { return verifyShuffles(v.Election) }})
+	}
+	steps = append(steps,
+		step{"Ballots homomorphic count", true, func() error {
+			count := Count(v.Election, counted, v.Weights)
+			r.EncryptedTally = tallyCiphertexts(count)
+			decrypted, err := DecryptResults(v.Election, res, count, v.Trustees, resultBound(res, r.TotalWeight))
+			if err == nil {
+				results = decrypted
+			}
+			return err
+		}},
+		step{"Decryption proofs", false, func() error { return verifyDecryptionFactors(v.Election, res, v.Trustees) }},
+		step{"Decrypted results", false, func() error { return verifyDecryptedResults(v.Election, results, res.Result) }},
+	)
+	if HasNonHomomorphic(v.Election) {
+		steps = append(steps, step{"Non-homomorphic results", false, func() error { return verifyNonHomomorphicResults(v.Election, res, v.Trustees) }})
+	}
+	r.Tally = append(r.Tally, runSteps(steps, v.All)...)
+	r.Results = results
+	return r
+}
+
+func tallyCiphertexts(count [][]Choice) [][]Ciphertext {
+	tally := make([][]Ciphertext, len(count))
+	for i, q := range count {
+		tally[i] = []Ciphertext{}
+		for _, c := range q {
+			tally[i] = append(tally[i], Ciphertext{Alpha: c.Alpha.String(), Beta: c.Beta.String()})
+		}
+	}
+	return tally
+}
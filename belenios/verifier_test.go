@@ -0,0 +1,198 @@
+package belenios
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVerifier(t *testing.T) {
+	elec, res, ballots, trustees := readTestData(t)
+	_, creds, _, _ := ReadCredentials("../dataTest")
+
+	progress := 0
+	v := Verifier{Election: elec, Trustees: trustees, Credentials: creds, Progress: func() { progress++ }}
+	r := v.Verify(ballots, res)
+	assert.Equal(t, nil, r.Err(), "Verify")
+	assert.Equal(t, "e1Jmque3h7bkC6gz/6mrSlOL/88MHP6L6wNGuDsRbHE", r.Fingerprint, "Fingerprint")
+	assert.Equal(t, 6, len(r.Setup), "Setup checks")
+	assert.Equal(t, len(ballots), len(r.Ballots), "Ballots checks")
+	assert.Equal(t, len(ballots), progress, "Progress")
+	assert.Equal(t, 3, r.CredentialsUsed, "Used credentials")
+	assert.Equal(t, 2, r.CredentialsUnused, "Unused credentials")
+	assert.Equal(t, len(ballots), r.Counted, "Counted ballots")
+	var names []string
+	for _, c := range r.Tally {
+		names = append(names, c.Name)
+	}
+	assert.Equal(t, []string{"Tally group membership", "Number of tallied ballots", "Ballots homomorphic count",
+		"Decryption proofs", "Decrypted results"}, names, "Tally checks")
+	assert.Equal(t, res.Result, r.Results, "Results")
+
+	// Stop at first bad ballot, in ballots order with workers
+	bad := append([]Ballot{}, ballots...)
+	bad[1].Signature.Challenge = "1"
+	for _, workers := range []int{1, 4} {
+		v.Workers = workers
+		r = v.Verify(bad, res)
+		assert.Equal(t, 2, len(r.Ballots), "Ballots checks")
+		assert.Contains(t, r.Err().Error(), " Ballot "+bad[1].Tracker+"\n Signature", "Bad ballot")
+		assert.Equal(t, 0, len(r.Tally), "No tally check")
+	}
+	v.Workers = 0
+
+	// Wrong result
+	wrong := res
+	wrong.Result = [][]int{{0}}
+	r = v.Verify(ballots, wrong)
+	assert.Equal(t, "Decrypted results", r.Tally[len(r.Tally)-1].Name, "Last check")
+	assert.NotEqual(t, nil, r.Err(), "Wrong result")
+
+	// Collect all failures, bad ballots are not counted
+	_, _, bad, _ = readTestData(t)
+	bad[1].Signature.Challenge = "1"
+	bad[2].Answers[1].IndividualProofs[0][0].Challenge = "1"
+	v.All = true
+	r = v.Verify(bad, res)
+	assert.Equal(t, len(bad), len(r.Ballots), "All ballots checks")
+	assert.Equal(t, 1, r.Counted, "Counted ballots")
+	var failures []Failure
+	for _, f := range r.Failures() {
+		f.Err = nil
+		failures = append(failures, f)
+	}
+	assert.Equal(t, []Failure{
+		{Ballot: 2, Tracker: bad[1].Tracker, Check: "Signature"},
+		{Ballot: 3, Tracker: bad[2].Tracker, Question: 2, Check: "Individual proofs"},
+		{Check: "Number of tallied ballots"},
+		{Check: "Ballots homomorphic count"},
+	}, failures, "Failures")
+	assert.Contains(t, r.Err().Error(), " Ballot "+bad[1].Tracker+"\n Signature", "First failure")
+	assert.Equal(t, "Number of tallied ballots", r.Tally[1].Name, "num_tallied check")
+	assert.Contains(t, r.Tally[1].Err.Error(), "  invalid, not counted: "+bad[1].Tracker+"\n  invalid, not counted: "+bad[2].Tracker+"\n", "Not counted trackers")
+
+	v.Workers = 3
+	assert.Equal(t, r, v.Verify(bad, res), "Same report with workers")
+
+	// Duplicate after an invalid ballot, numbered in ballots.jsons
+	_, _, ballots, _ = readTestData(t)
+	revote := append(append([]Ballot{}, ballots...), ballots[1])
+	revote[0].Signature.Challenge = "1"
+	v.Revote = true
+	r = v.Verify(revote, res)
+	assert.Equal(t, []Duplicate{{Index: 2, Tracker: ballots[1].Tracker, ReplacedBy: ballots[1].Tracker}}, r.Duplicates, "Duplicate index")
+	v.Revote = false
+
+	// Tally missing a question stops before decryption
+	_, _, ballots, _ = readTestData(t)
+	short := res
+	short.EncryptedTally = res.EncryptedTally[:len(res.EncryptedTally)-1]
+	r = v.Verify(ballots, short)
+	assert.Equal(t, "Tally group membership", r.Tally[len(r.Tally)-1].Name, "Stopped at tally shape")
+	assert.Contains(t, r.Err().Error(), "Encrypted tally with 3 questions\n  for 4 questions", "Tally shape")
+	short = res
+	short.PartialDecryptions = append([]PartialDecryption{}, res.PartialDecryptions...)
+	short.PartialDecryptions[0].DecryptionFactors = short.PartialDecryptions[0].DecryptionFactors[:1]
+	r = v.Verify(ballots, short)
+	assert.Contains(t, r.Err().Error(), "Partial decryption 1\n  not matching 4 questions", "Partial decryption shape")
+}
+
+func BenchmarkVerifyBallots(b *testing.B) {
+	elec, _, ballots, trustees := readTestData(b)
+	var many []Ballot
+	for len(many) < 16 {
+		many = append(many, ballots...)
+	}
+	for _, workers := range []int{1, 2, 4, 8} {
+		b.Run(fmt.Sprintf("workers=%d", workers), func(b *testing.B) {
+			v := Verifier{Election: elec, Trustees: trustees, Workers: workers}
+			for n := 0; n < b.N; n++ {
+				v.verifyBallots(many, func(int, []Check) bool { return true })
+			}
+		})
+	}
+}
+
+func TestVerifierMixed(t *testing.T) {
+	elec, _, _, _ := readTestData(t)
+	json.Unmarshal([]byte(`"RFC-3526-2048"`), &elec.PublicKey.Group)
+	tg := newTestGroup(elec)
+	g := tg.G()
+
+	x := tg.random()
+	y := tg.Exp(g, x)
+	trustees := []Trustee{{Kind: "Single", Single: &TrusteePublicKey{}}}
+	*trustees[0].Single = tg.publicKey(x)
+	elec.PublicKey.Y = y.String()
+	h := Question{Answers: []string{"X", "Y"}, Min: 1, Max: 1, Question: "Choose", Type: QuestionHomomorphic}
+	nh := Question{Answers: []string{"A", "B", "C"}, Question: "Rank", Type: QuestionNonHomomorphic}
+	elec.Questions = []Question{h, nh}
+	elec.Fingerprint = ""
+
+	// Ballots with a homomorphic and a non-homomorphic answer
+	choices := []int{0, 1, 1}
+	votes := [][]int{{1, 2, 3}, {3, 1, 2}, {2, 3, 1}}
+	var ballots []Ballot
+	for i, v := range votes {
+		sk := tg.random()
+		S := tg.Exp(g, sk)
+		M, err := nhPlaintext(tg, nh, v)
+		assert.Equal(t, nil, err, "nhPlaintext")
+		b := Ballot{ElectionHash: Fingerprint(elec), ElectionUUID: elec.UUID}
+		b.Answers = []Answer{tg.hAnswer(y, S, h, choices[i]), tg.nhAnswer(y, S, M)}
+		tg.signBallot(sk, &b)
+		ballots = append(ballots, b)
+	}
+
+	// Tally: homomorphic count and ballots ciphertexts, decrypted by the trustee
+	var in []Ciphertext
+	for _, b := range ballots {
+		in = append(in, b.Answers[1].NonHomomorphic.Choices)
+	}
+	res := Result{NumTallied: len(ballots), Result: [][]int{{1, 2}, nil}}
+	res.NonHomomorphicResult = [][][]int{nil, nil}
+	var tally [][]Ciphertext
+	tally = append(tally, tallyCiphertexts(Count(elec, ballots, nil))[0], in)
+	pd := PartialDecryption{DecryptionFactors: make([][]string, 2), DecryptionProofs: make([][]Proof, 2)}
+	for i, cs := range tally {
+		res.EncryptedTally = append(res.EncryptedTally, nil)
+		for _, c := range cs {
+			res.EncryptedTally[i] = append(res.EncryptedTally[i], c)
+			alpha, _ := tg.Parse(c.Alpha)
+			f := tg.Exp(alpha, x)
+			pd.DecryptionFactors[i] = append(pd.DecryptionFactors[i], f.String())
+			pd.DecryptionProofs[i] = append(pd.DecryptionProofs[i], tg.prove(x, fmt.Sprintf("decrypt|%s|", y), g, alpha))
+			if i == 1 {
+				beta, _ := tg.Parse(c.Beta)
+				for _, v := range votes {
+					if E, _ := nhPlaintext(tg, nh, v); tg.Equal(E, tg.Mul(beta, tg.Inv(f))) {
+						res.NonHomomorphicResult[1] = append(res.NonHomomorphicResult[1], v)
+					}
+				}
+			}
+		}
+	}
+	res.PartialDecryptions = []PartialDecryption{pd}
+
+	// Shuffles not verified, other checks with All
+	v := Verifier{Election: elec, Trustees: trustees}
+	r := v.Verify(ballots, res)
+	assert.Equal(t, "Mixnet shuffles", r.Tally[len(r.Tally)-1].Name, "Stop at mixnet shuffles")
+	v.All = true
+	r = v.Verify(ballots, res)
+	var names []string
+	for _, c := range r.Tally {
+		names = append(names, c.Name)
+	}
+	assert.Equal(t, []string{"Tally group membership", "Number of tallied ballots", "Mixnet shuffles",
+		"Ballots homomorphic count", "Decryption proofs", "Decrypted results", "Non-homomorphic results"}, names, "Tally checks")
+	assert.Equal(t, []Failure{{Check: "Mixnet shuffles", Err: r.Tally[2].Err}}, r.Failures(), "Only shuffles not verified")
+	assert.Equal(t, res.Result, r.Results, "Homomorphic results")
+
+	// Wrong homomorphic result
+	res.Result = [][]int{{2, 1}, nil}
+	r = v.Verify(ballots, res)
+	assert.Equal(t, "Decrypted results", r.Failures()[1].Check, "Wrong result")
+}
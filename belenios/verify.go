@@ -0,0 +1,593 @@
+package belenios
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// x in range [0, q)
+func isInRange(x, q *big.Int) bool {
+	return x != nil && x.Sign() >= 0 && x.Cmp(q) < 0
+}
+
+// Group elements in subgroup and proofs in [0, q)
+func checkGroupAndRange(grp Group, elements []string, proofs []Proof) (string, bool) {
+	for _, e := range elements {
+		x, err := grp.Parse(e)
+		if err != nil || !grp.IsMember(x) {
+			return fmt.Sprintf("element %s not in group", e), false
+		}
+	}
+	for _, p := range proofs {
+		c, _ := new(big.Int).SetString(p.Challenge, 10)
+		r, _ := new(big.Int).SetString(p.Response, 10)
+		if !isInRange(c, grp.Q()) || !isInRange(r, grp.Q()) {
+			return fmt.Sprintf("challenge %s or response %s not in [0, q)", p.Challenge, p.Response), false
+		}
+	}
+	return "", true
+}
+
+// Smart ballot tracker
+//
+//	BASE64(SHA256(ballot)) of the raw json ballot
+func ballotTracker(raw []byte) string {
+	hashB := sha256.Sum256(raw)
+	return base64.RawStdEncoding.EncodeToString(hashB[:])
+}
+
+// Find ballot by tracker
+func FindBallot(ballots []Ballot, tracker string) (Ballot, error) {
+	for _, b := range ballots {
+		if b.Tracker == tracker {
+			return b, nil
+		}
+	}
+	return Ballot{}, fmt.Errorf(" No ballot with tracker %s\n", tracker)
+}
+
+// Ballot verifications, proofs need well formed answers in group
+func ballotSteps(b Ballot, elec Election, hash string) []step {
+	return []step{
+		{"Election fingerprint", false, func() error { return verifyResponseToElection(b, elec.UUID, hash) }},
+		{"Answers", true, func() error { return verifyBallotAnswers(b, elec) }},
+		{"Group membership", true, func() error { return verifyBallotGroupMembership(b, elec) }},
+		{"Signature", false, func() error { return verifyBallotSignature(b, elec) }},
+		{"Blank proofs", false, func() error { return verifyBallotBlankProofs(b, elec) }},
+		{"Overall proofs", false, func() error { return verifyBallotOverallProofs(b, elec) }},
+		{"Non-homomorphic proofs", false, func() error { return verifyBallotNonHomomorphicProofs(b, elec) }},
+		{"Individual proofs", false, func() error { return verifyBallotIndividualProofs(b, elec) }},
+	}
+}
+
+// Run all ballot verifications
+func verifyBallot(b Ballot, elec Election, hash string) error {
+	for _, c := range runSteps(ballotSteps(b, elec, hash), false) {
+		if c.Err != nil {
+			return c.Err
+		}
+	}
+	return nil
+}
+
+// Error of the answer to a question
+type QuestionError struct {
+	Question int // from 1
+	Err      error
+}
+
+func (e QuestionError) Error() string { return e.Err.Error() }
+
+func (e QuestionError) Unwrap() error { return e.Err }
+
+func questionErrorf(i int, format string, a ...interface{}) error {
+	return QuestionError{Question: i + 1, Err: fmt.Errorf(format, a...)}
+}
+
+func verifyResponseToElection(b Ballot, uuid string, hash string) error {
+	// [4.14] fingerprint of election
+	//  HJSON(J) = BASE64(SHA256(J))
+	// Computed from raw election.json bytes, see electionFingerprint
+	if b.ElectionUUID != uuid {
+		return fmt.Errorf(" Ballot with Election UUID %s\n  from wrong Election\n", b.ElectionUUID)
+	}
+	if b.ElectionHash != hash {
+		return fmt.Errorf(" Ballot with Election Hash %s\n  from wrong Election\n", b.ElectionHash)
+	}
+	return nil // no error
+}
+
+func verifyBallotSignature(b Ballot, elec Election) error {
+	grp := electionGroup(elec)
+	g, q := grp.G(), grp.Q()
+
+	bsPK, _ := grp.Parse(b.Signature.PublicKey)
+	bsr, _ := new(big.Int).SetString(b.Signature.Response, 10)
+	bsc, _ := new(big.Int).SetString(b.Signature.Challenge, 10)
+
+	var bCyphers []string
+	for _, ai := range b.Answers {
+		for _, ci := range ai.Ciphertexts() {
+			bCyphers = append(bCyphers, ci.Alpha)
+			bCyphers = append(bCyphers, ci.Beta)
+		}
+	}
+
+	// [4.13] Signature
+	// A = g**response * public_key**challenge
+	A := grp.Mul(grp.Exp(g, bsr), grp.Exp(bsPK, bsc))
+
+	// SHA256(sig|public_key|A|alpha(γ1),beta(γ1),...,alpha(γl),beta(γl)) mod q
+	Hsign := fmt.Sprintf("sig|%s|%s|%s", bsPK, A, strings.Join(bCyphers, ","))
+	left := hashQ(Hsign, q)
+
+	if left.Cmp(bsc) != 0 {
+		return fmt.Errorf(" Signature ballot with challenge\n  %s\n  KO !!!!!\n", bsc)
+	}
+	return nil // no error
+}
+
+func verifyBallotBlankProofs(b Ballot, elec Election) error {
+	grp := electionGroup(elec)
+	g, q := grp.G(), grp.Q()
+	y, _ := grp.Parse(elec.PublicKey.Y)
+
+	bsPK, _ := grp.Parse(b.Signature.PublicKey)
+
+	for i, a := range b.Answers {
+		if !elec.Questions[i].Blank || a.NonHomomorphic != nil {
+			continue // no blank proof for non-homomorphic answers
+		}
+		a0, _ := grp.Parse(a.Choices[0].Alpha)
+		b0, _ := grp.Parse(a.Choices[0].Beta)
+		r0, _ := new(big.Int).SetString(a.BlankProof[0].Response, 10)
+		c0, _ := new(big.Int).SetString(a.BlankProof[0].Challenge, 10)
+
+		// Homomorphic Sum
+		aSum := grp.One()
+		bSum := grp.One()
+		for i, c := range a.Choices {
+			if i == 0 {
+				continue
+			}
+			a1, _ := grp.Parse(c.Alpha)
+			aSum = grp.Mul(aSum, a1)
+			b1, _ := grp.Parse(c.Beta)
+			bSum = grp.Mul(bSum, b1)
+		}
+
+		r1, _ := new(big.Int).SetString(a.BlankProof[1].Response, 10)
+		c1, _ := new(big.Int).SetString(a.BlankProof[1].Challenge, 10)
+
+		// [4.12] Proofs
+		// P = "g,y,a0,b0,aS,bS"
+		P := fmt.Sprintf("%s,%s,", g, y)
+		P += fmt.Sprintf("%s,%s,%s,%s", a0, b0, aSum, bSum)
+
+		// [4.12.3] Verifyink blank_proof
+		// A0 = g**response0 x alpha0**challenge0
+		// B0 = y**response0 x beta0**challenge0
+		A0 := grp.Mul(grp.Exp(g, r0), grp.Exp(a0, c0))
+		B0 := grp.Mul(grp.Exp(y, r0), grp.Exp(b0, c0))
+
+		// A1 = g**response1 x alphaS**challenge1
+		// B1 = y**response1 x betaS**challenge1
+		A1 := grp.Mul(grp.Exp(g, r1), grp.Exp(aSum, c1))
+		B1 := grp.Mul(grp.Exp(y, r1), grp.Exp(bSum, c1))
+
+		// [4.12.1] overall_proof signature
+		// "bproof0|public_key|P|A0,B0,A1,B1
+		HString := fmt.Sprintf("bproof0|%s|%s|%s,%s,%s,%s", bsPK, P, A0, B0, A1, B1)
+		// SUM256("...") mod q
+		left := hashQ(HString, q)
+
+		// ( challenge0 +  challenge1 ) mod q
+		right := new(big.Int).Mod(c0.Add(c0, c1), q)
+
+		if left.Cmp(right) != 0 {
+			return questionErrorf(i, " Blank Proof answer %d\n   K0 !!!!!\n", i+1)
+		}
+	}
+
+	return nil // no error
+}
+
+func verifyBallotIndividualProofs(b Ballot, elec Election) error {
+	grp := electionGroup(elec)
+	g, q := grp.G(), grp.Q()
+	y, _ := grp.Parse(elec.PublicKey.Y)
+
+	bsPK, _ := grp.Parse(b.Signature.PublicKey)
+
+	// [4.10.1] individual_proofs for homomorphic answer
+	//  iprove(S,r,m,0,1)
+	// [4.11]  Proofs of interval membership (0..1)
+	//  SUM256("prove|S|α,β|A0,B0,...,Ak,Bk") mos q = total challenges
+	for ia, a := range b.Answers {
+		for ic, c := range a.Choices {
+			alpha0, _ := grp.Parse(c.Alpha) // alpha
+			beta0, _ := grp.Parse(c.Beta)   // beta
+			ind := a.IndividualProofs[ic]   // IndividualProof for alpha and beta
+
+			tc := big.NewInt(0) // total challenges
+			M := ""
+			for _, m := range []int{0, 1} {
+				r0, _ := new(big.Int).SetString(ind[m].Response, 10)
+				c0, _ := new(big.Int).SetString(ind[m].Challenge, 10)
+				tc = tc.Add(tc, c0).Mod(tc, q)
+
+				// [4.11] proofs of interval
+				// A = g**r / alpha**c
+				A0 := grp.Mul(grp.Exp(g, r0), grp.Inv(grp.Exp(alpha0, c0)))
+
+				// B = y**r / (beta/(g**m)) ** c
+				gEm := grp.Exp(g, big.NewInt(int64(m))) // g**m
+				b0Dg := grp.Mul(beta0, grp.Inv(gEm))    // beta/(g**m)
+				B0 := grp.Mul(grp.Exp(y, r0), grp.Inv(grp.Exp(b0Dg, c0)))
+
+				M += fmt.Sprintf(",%s,%s", A0, B0)
+			}
+			// [4.10.1] individual_proofs for homomorphic answer
+			// iprove(S,r,m,0,1)
+			// [4.11] "prove|S|α,β|A0,B0,...,Ak,Bk"
+			HString := fmt.Sprintf("prove|%s|%s,%s|%s", bsPK, alpha0, beta0, M[1:]) // "M[1:]" remove first ","
+
+			// SUM256("...") mod q
+			left := hashQ(HString, q)
+
+			// ( challenge0 + challenge1 + challenge ..) mod q
+			right := tc
+
+			if left.Cmp(right) != 0 {
+				return questionErrorf(ia, " Overall Proof for ballot with PublicKey\n  %s\n  KO !!!!!\n", bsPK)
+			}
+		}
+	}
+
+	return nil // no error
+}
+
+func verifyBallotOverallProofs(b Ballot, elec Election) error {
+	grp := electionGroup(elec)
+	g, q := grp.G(), grp.Q()
+	y, _ := grp.Parse(elec.PublicKey.Y)
+
+	bsPK, _ := grp.Parse(b.Signature.PublicKey)
+
+	for ia, a := range b.Answers {
+		if a.NonHomomorphic != nil {
+			continue
+		}
+		// [4.12] Proofs
+		// P = "g,y,alpha,beta,aSum,bSum"
+
+		// Homomorphic Sum
+		aSum := grp.One()
+		bSum := grp.One()
+		for i, c := range a.Choices {
+			if elec.Questions[ia].Blank == true && i == 0 {
+				continue
+			}
+			alpha, _ := grp.Parse(c.Alpha)
+			aSum = grp.Mul(aSum, alpha)
+			beta, _ := grp.Parse(c.Beta)
+			bSum = grp.Mul(bSum, beta)
+
+		}
+
+		tc := big.NewInt(0) // total challenges
+
+		HString := "" // signature
+
+		if elec.Questions[ia].Blank == true {
+			// [4.12.1] overall_proof signature with blank
+			// SUM256("bproof0|public_key|P|A0,B0,A1,B1, A.., B..) mod q
+			HString = fmt.Sprintf("bproof1|%s|", bsPK)
+		} else {
+			// [4.10.1] overall_proof signature without blank : prove interval
+			// iprove(S,R,M−min,min,...,max)
+			// [4.11] "prove|S|α,β|A0,B0,...,Ak,Bk"
+			HString = fmt.Sprintf("prove|%s|%s,%s|", bsPK, aSum, bSum)
+		}
+
+		// A0, B0 for Question with blank
+		if elec.Questions[ia].Blank == true {
+			alpha0, _ := grp.Parse(a.Choices[0].Alpha)
+			beta0, _ := grp.Parse(a.Choices[0].Beta)
+			r0, _ := new(big.Int).SetString(a.OverallProof[0].Response, 10)
+			c0, _ := new(big.Int).SetString(a.OverallProof[0].Challenge, 10)
+
+			tc = c0
+
+			// [4.12] Proofs
+			// P = "g,y,alpha0,beta0,aS,bS"
+			P := fmt.Sprintf("%s,%s,%s,%s,%s,%s", g, y, alpha0, beta0, aSum, bSum)
+			HString += fmt.Sprintf("%s|", P)
+
+			// [4.12.3] Verifyink overall_proof
+			// A0 = g**response0 x alpha0**challenge0
+			// B0 = y**response0 x (beta0/g)**challenge0
+			A0 := grp.Mul(grp.Exp(g, r0), grp.Exp(alpha0, c0))
+			b0Dg := grp.Mul(beta0, grp.Inv(g))
+			B0 := grp.Mul(grp.Exp(y, r0), grp.Exp(b0Dg, c0))
+
+			// Add A0, B0
+			HString += fmt.Sprintf("%s,%s,", A0, B0)
+
+		}
+
+		M := ""
+		min := elec.Questions[ia].Min
+		max := elec.Questions[ia].Max
+
+		for k := min; k <= max; k++ {
+			im := k - min // challenge, response array index
+			if elec.Questions[ia].Blank == true {
+				im += 1 // Blank vote is stored in head of array
+			}
+			r, _ := new(big.Int).SetString(a.OverallProof[im].Response, 10)
+			c, _ := new(big.Int).SetString(a.OverallProof[im].Challenge, 10)
+
+			// ( challenge0 + challenge1 + challenge.. ) mod q
+			tc = tc.Mod(tc.Add(tc, c), q)
+
+			// [4.12.3] Verifyink overall_proof
+			// A = g**response x alpha**challenge
+			// B = y**response x (beta/(g**k))**challenge
+			// [4.10.1] Interval for non blank
+			// A = g**response / alpha**challenge
+			// B = y**response / (beta/(g**k))**challenge
+			a1a := grp.Exp(g, r)    // g**response
+			a1b := grp.Exp(aSum, c) // alpha**challenge
+
+			gEm := grp.Exp(g, big.NewInt(int64(k))) // g**k
+			b1a := grp.Exp(y, r)                    // y**response
+			b1Dg := grp.Mul(bSum, grp.Inv(gEm))     // beta/(g**k)
+			b1b := grp.Exp(b1Dg, c)                 // (beta/(g**k))**challenge
+
+			var A, B Element
+			if elec.Questions[ia].Blank == true {
+				// [4.12.3] Verifyink overall_proof
+				A = grp.Mul(a1a, a1b)
+				B = grp.Mul(b1a, b1b)
+			} else {
+				// [4.10.1] Interval for non blank
+				A = grp.Mul(a1a, grp.Inv(a1b))
+				B = grp.Mul(b1a, grp.Inv(b1b))
+			}
+
+			// Add A0,B0,...,Am,Bm for prove
+			//  or A0,B0,...,Am,Bm for bproof1
+			M += fmt.Sprintf(",%s,%s", A, B)
+		}
+
+		HString += M[1:] // M[1:] Remove first ","
+
+		// SUM256("...") mod q
+		left := hashQ(HString, q)
+
+		// ( challenge0 + challenge1 + challenge ..) mod q
+		right := tc
+
+		if left.Cmp(right) != 0 {
+			return questionErrorf(ia, " Overall Proof answer %d\n   K0 !!!!!\n%s", ia+1, b.Signature.Challenge)
+		}
+	}
+
+	return nil // no error
+}
+
+// Proofs of non-homomorphic answers
+func verifyBallotNonHomomorphicProofs(b Ballot, elec Election) error {
+	grp := electionGroup(elec)
+	g, q := grp.G(), grp.Q()
+	y, _ := grp.Parse(elec.PublicKey.Y)
+
+	bsPK, _ := grp.Parse(b.Signature.PublicKey)
+
+	for ia, a := range b.Answers {
+		if a.NonHomomorphic == nil {
+			continue
+		}
+		alpha, _ := grp.Parse(a.NonHomomorphic.Choices.Alpha)
+		beta, _ := grp.Parse(a.NonHomomorphic.Choices.Beta)
+		r, _ := new(big.Int).SetString(a.NonHomomorphic.Proof.Response, 10)
+		c, _ := new(big.Int).SetString(a.NonHomomorphic.Proof.Challenge, 10)
+
+		// Proof of knowledge of r with alpha = g**r
+		// A = g**response x alpha**challenge
+		A := grp.Mul(grp.Exp(g, r), grp.Exp(alpha, c))
+
+		// SUM256("raweg|S|y,alpha,beta|A") mod q
+		HString := fmt.Sprintf("raweg|%s|%s,%s,%s|%s", bsPK, y, alpha, beta, A)
+		left := hashQ(HString, q)
+
+		if left.Cmp(c) != 0 {
+			return questionErrorf(ia, " Non-homomorphic Proof answer %d\n  KO !!!!!\n", ia+1)
+		}
+	}
+
+	return nil // no error
+}
+
+// Decrypted homomorphic results match result.json,
+// non-homomorphic questions are verified by verifyNonHomomorphicResults
+func verifyDecryptedResults(elec Election, a, b [][]int) error {
+	if len(a) != len(b) || len(a) != len(elec.Questions) {
+		return fmt.Errorf(" not matching length\n    decrypted: %+v\n results.json: %+v\n", a, b)
+	}
+	for i1, v1 := range a {
+		if elec.Questions[i1].Type != QuestionHomomorphic {
+			continue
+		}
+		if len(v1) != len(b[i1]) {
+			return fmt.Errorf(" not matching length\n    decrypted: %+v\n results.json: %+v\n", a, b)
+		}
+		for i2, v2 := range v1 {
+			if v2 != b[i1][i2] {
+				return fmt.Errorf(" not matching values\n    decrypted: %+v\n results.json: %+v\n", a, b)
+			}
+		}
+	}
+	return nil
+}
+
+func verifyDecryptionFactors(elec Election, res Result, trustees []Trustee) error {
+	grp := electionGroup(elec)
+	g, q := grp.G(), grp.Q()
+
+	keys, err := decryptionKeys(trustees, elec)
+	if err != nil {
+		return err
+	}
+	owners, err := partialOwners(res, keys, trustees)
+	if err != nil {
+		return err
+	}
+
+	for ip, partial := range res.PartialDecryptions {
+		name := keys[owners[ip]].Name
+		X := keys[owners[ip]].PublicKey
+
+		if len(partial.DecryptionFactors) != len(res.EncryptedTally) ||
+			len(partial.DecryptionProofs) != len(res.EncryptedTally) {
+			return fmt.Errorf(" Partial decryption of trustee %s\n  not matching encrypted tally\n", name)
+		}
+
+		for i, question := range res.EncryptedTally {
+			if len(partial.DecryptionFactors[i]) != len(question) ||
+				len(partial.DecryptionProofs[i]) != len(question) {
+				return questionErrorf(i, " Partial decryption of trustee %s\n  not matching encrypted tally for question %d\n", name, i+1)
+			}
+			for j, c := range question {
+				alpha, _ := grp.Parse(c.Alpha)
+				f, errf := grp.Parse(partial.DecryptionFactors[i][j])
+				r, okr := new(big.Int).SetString(partial.DecryptionProofs[i][j].Response, 10)
+				ch, okc := new(big.Int).SetString(partial.DecryptionProofs[i][j].Challenge, 10)
+				if alpha == nil || errf != nil || !okr || !okc {
+					return questionErrorf(i, " Bad partial decryption of trustee %s\n  question %d\n", name, i+1)
+				}
+
+				// [4.16] Partial decryptions
+				// A = g**response / public_key**challenge
+				// B = alpha**response / factor**challenge
+				A := grp.Mul(grp.Exp(g, r), grp.Inv(grp.Exp(X, ch)))
+				B := grp.Mul(grp.Exp(alpha, r), grp.Inv(grp.Exp(f, ch)))
+
+				// SUM256("decrypt|public_key|A,B") mod q
+				HString := fmt.Sprintf("decrypt|%s|%s,%s", X, A, B)
+				left := hashQ(HString, q)
+
+				if left.Cmp(ch) != 0 {
+					answer := fmt.Sprintf("%d", j+1)
+					if i < len(elec.Questions) && elec.Questions[i].Blank {
+						answer = fmt.Sprintf("%d", j)
+						if j == 0 {
+							answer = "blank"
+						}
+					}
+					return questionErrorf(i, " Decryption proof of trustee %s\n  question %d, answer %s\n  KO !!!!!\n", name, i+1, answer)
+				}
+			}
+		}
+	}
+
+	return nil // no error
+}
+
+func verifyBallotGroupMembership(b Ballot, elec Election) error {
+	grp := electionGroup(elec)
+
+	// Signature
+	msg, ok := checkGroupAndRange(grp, []string{b.Signature.PublicKey},
+		[]Proof{{Challenge: b.Signature.Challenge, Response: b.Signature.Response}})
+	if !ok {
+		return fmt.Errorf(" Signature ballot with public key\n  %s\n  %s\n", b.Signature.PublicKey, msg)
+	}
+
+	for ia, a := range b.Answers {
+		var elements []string
+		var proofs []Proof
+		for _, c := range a.Ciphertexts() {
+			elements = append(elements, c.Alpha, c.Beta)
+		}
+		for _, ind := range a.IndividualProofs {
+			proofs = append(proofs, ind...)
+		}
+		proofs = append(proofs, a.OverallProof...)
+		proofs = append(proofs, a.BlankProof...)
+		if a.NonHomomorphic != nil {
+			proofs = append(proofs, a.NonHomomorphic.Proof)
+		}
+		msg, ok := checkGroupAndRange(grp, elements, proofs)
+		if !ok {
+			return questionErrorf(ia, " Ballot answer %d\n  %s\n", ia+1, msg)
+		}
+	}
+
+	return nil // no error
+}
+
+// Encrypted tally and partial decryptions sized by questions and answers
+func verifyTallyShape(elec Election, res Result) error {
+	if len(res.EncryptedTally) != len(elec.Questions) {
+		return fmt.Errorf(" Encrypted tally with %d questions\n  for %d questions\n", len(res.EncryptedTally), len(elec.Questions))
+	}
+	for i, q := range elec.Questions {
+		n := len(q.Answers)
+		if q.Blank {
+			n++
+		}
+		if q.Type == QuestionHomomorphic && len(res.EncryptedTally[i]) != n {
+			return questionErrorf(i, " Encrypted tally question %d\n  %d ciphertexts for %d answers\n", i+1, len(res.EncryptedTally[i]), n)
+		}
+	}
+	for ip, partial := range res.PartialDecryptions {
+		if len(partial.DecryptionFactors) != len(elec.Questions) || len(partial.DecryptionProofs) != len(elec.Questions) {
+			return fmt.Errorf(" Partial decryption %d\n  not matching %d questions\n", ip+1, len(elec.Questions))
+		}
+		for i, cs := range res.EncryptedTally {
+			if len(partial.DecryptionFactors[i]) != len(cs) || len(partial.DecryptionProofs[i]) != len(cs) {
+				return questionErrorf(i, " Partial decryption %d question %d\n  not matching %d ciphertexts\n", ip+1, i+1, len(cs))
+			}
+		}
+	}
+	return nil // no error
+}
+
+func verifyTallyGroupMembership(elec Election, res Result) error {
+	grp := electionGroup(elec)
+
+	if err := verifyTallyShape(elec, res); err != nil {
+		return err
+	}
+
+	for i, question := range res.EncryptedTally {
+		var elements []string
+		for _, c := range question {
+			elements = append(elements, c.Alpha, c.Beta)
+		}
+		msg, ok := checkGroupAndRange(grp, elements, nil)
+		if !ok {
+			return questionErrorf(i, " Encrypted tally question %d\n  %s\n", i+1, msg)
+		}
+	}
+
+	for ip, partial := range res.PartialDecryptions {
+		for i := range partial.DecryptionFactors {
+			var proofs []Proof
+			if i < len(partial.DecryptionProofs) {
+				proofs = partial.DecryptionProofs[i]
+			}
+			msg, ok := checkGroupAndRange(grp, partial.DecryptionFactors[i], proofs)
+			if !ok {
+				return questionErrorf(i, " Partial decryption %d question %d\n  %s\n", ip+1, i+1, msg)
+			}
+		}
+	}
+
+	return nil // no error
+}
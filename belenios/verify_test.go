@@ -0,0 +1,115 @@
+package belenios
+
+import (
+//	"crypto/sha256"
+//	"encoding/base64"
+//	"encoding/json"
+//	"fmt"
+
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func readTestData(t testing.TB) (Election, Result, []Ballot, []Trustee) {
+	elec, res, ballots, trustees, err := ReadFiles("../dataTest")
+	assert.Equal(t, nil, err, "ReadFiles")
+	return elec, res, ballots, trustees
+}
+
+func prime(elec Election) *big.Int {
+	p, _ := new(big.Int).SetString(elec.PublicKey.Group.P, 10)
+	return p
+}
+
+func TestVerify(t *testing.T) {
+	elec, res, ballots, trustees := readTestData(t)
+
+	//var s []byte
+	//s, _ = json.MarshalIndent(ballots[0], "", " ")
+	//fmt.Print("\n==Ballot 0==\n", string(s))
+	//s, _ = json.MarshalIndent(elec, "", " ")
+	//fmt.Print("\n==Élection==\n", string(s))
+	//s, _ = json.MarshalIndent(res, "", " ")
+	//fmt.Print("\n==Résultats==\n", string(s))
+
+	HJSON := Fingerprint(elec)
+	//jsonElec, _ := json.Marshal(elec)
+	//hashJ := sha256.Sum256(jsonElec)
+	//HJSON := base64.RawStdEncoding.EncodeToString(hashJ[:])
+
+	b := ballots[0]
+	assert.Equal(t, "CafLARwHIWiDOSQCOtqE7tq0ULTWZOzyomKF1ajAl7M", b.Tracker, "Ballot tracker")
+	found, err := FindBallot(ballots, "A25hWwkMU5oE7qfUgywaH0mKZO0TfmE4Q8zZCX8xK0I")
+	assert.Equal(t, nil, err, "FindBallot")
+	assert.Equal(t, ballots[2], found, "Ballot found by tracker")
+	_, err = FindBallot(ballots, "unknown")
+	assert.NotEqual(t, nil, err, "No ballot")
+	assert.Equal(t, nil, verifyBallot(found, elec, HJSON), "verifyBallot")
+
+	err = verifyResponseToElection(b, elec.UUID, HJSON)
+	assert.Equal(t, nil, err, "Verify UUID and Hash")
+
+	err = verifyBallotGroupMembership(b, elec)
+	assert.Equal(t, nil, err, "verifyBallotGroupMembership")
+
+	err = verifyBallotSignature(b, elec)
+	assert.Equal(t, nil, err, "verifyBallotSignature")
+
+	err = verifyBallotBlankProofs(b, elec)
+	assert.Equal(t, nil, err, "verifyBallotBlankProofs")
+
+	err = verifyBallotOverallProofs(b, elec)
+	assert.Equal(t, nil, err, "verifyBallotOverallProofs")
+	err = verifyBallotIndividualProofs(b, elec)
+	assert.Equal(t, nil, err, "verifyBallotIndividualProofs")
+
+	count := Count(elec, ballots, nil)
+	assert.Equal(t, 4, len(count), "4 Questions in Count")
+	assert.Equal(t, 4, len(count[0]), "4 Answers in first Question")
+
+	results, err := DecryptResults(elec, res, count, trustees, res.NumTallied)
+	assert.Equal(t, nil, err, "DecryptAndPrint")
+	assert.Equal(t, 4, len(results), "4 Questions in Count")
+	assert.Equal(t, 4, len(results[0]), "4 Answers in first Question")
+
+	err = verifyDecryptedResults(elec, results, res.Result)
+	assert.Equal(t, nil, err, "Same calculated results")
+
+	assert.Equal(t, 1, len(trustees), "1 trustee")
+	err = verifyTallyGroupMembership(elec, res)
+	assert.Equal(t, nil, err, "verifyTallyGroupMembership")
+
+	err = verifyDecryptionFactors(elec, res, trustees)
+	assert.Equal(t, nil, err, "verifyDecryptionFactors")
+
+	// Tampered decryption factor
+	f := res.PartialDecryptions[0].DecryptionFactors[1][2]
+	res.PartialDecryptions[0].DecryptionFactors[1][2] = elec.PublicKey.Group.G
+	err = verifyDecryptionFactors(elec, res, trustees)
+	assert.NotEqual(t, nil, err, "verifyDecryptionFactors with bad factor")
+	assert.Contains(t, err.Error(), "trustee server\n  question 2, answer 3", "Error names trustee, question and answer")
+	res.PartialDecryptions[0].DecryptionFactors[1][2] = f
+
+	// Not in group: p - 1 has order 2
+	p1 := new(big.Int).Sub(prime(elec), big.NewInt(1)).String()
+	alpha := b.Answers[0].Choices[1].Alpha
+	b.Answers[0].Choices[1].Alpha = p1
+	err = verifyBallotGroupMembership(b, elec)
+	assert.Contains(t, err.Error(), "Ballot answer 1\n  element "+p1+" not in group", "Alpha not in group")
+	b.Answers[0].Choices[1].Alpha = alpha
+
+	response := b.Signature.Response
+	b.Signature.Response = elec.PublicKey.Group.Q
+	err = verifyBallotGroupMembership(b, elec)
+	assert.Contains(t, err.Error(), "not in [0, q)", "Response out of range")
+	b.Signature.Response = response
+
+	f = res.PartialDecryptions[0].DecryptionFactors[3][0]
+	res.PartialDecryptions[0].DecryptionFactors[3][0] = "1"
+	err = verifyTallyGroupMembership(elec, res)
+	assert.Contains(t, err.Error(), "Partial decryption 1 question 4\n  element 1 not in group", "Factor not in group")
+	res.PartialDecryptions[0].DecryptionFactors[3][0] = f
+
+}
@@ -1,9 +1,7 @@
 package main
 
 import (
-	"bufio"
-	"crypto/sha256"
-	"encoding/base64"
+	"bytes"
 	"encoding/json"
 	"flag"
 	"fmt"
@@ -12,8 +10,11 @@ import (
 	"net/http"
 	"net/url"
 	"os"
+	"path"
 	"regexp"
+	"strings"
 
+	"borvo/belenios"
 	"github.com/gookit/color"
 	"github.com/schollz/progressbar/v3"
 )
@@ -21,26 +22,16 @@ import (
 var (
 	bar     *progressbar.ProgressBar
 	Version string
-	Test    bool
 )
 
 /**
  Tools
 **/
 
-func OK(msg string) {
-	if msg != "" {
-		fmt.Println(msg)
-	}
-	if Test == false {
-		bar.Add(1)
-	}
-}
-
 func Error(msg string) {
 	fmt.Println()
 	color.Printf("<error>ERROR</>\t%s\n", msg)
-	os.Exit(0)
+	os.Exit(1)
 }
 
 func IsEmptyDir(name string) (bool, error) {
@@ -58,72 +49,190 @@ func IsEmptyDir(name string) (bool, error) {
 }
 
 // read Data from json files
-func readData(files [4]string, dir string) (Election, Result, []Ballot) {
-	var (
-		elec    Election
-		res     Result
-		ballots []Ballot
-	)
-	for _, file := range files {
-		jsonFile, err := os.Open(dir + "/" + file)
-		if err != nil {
-			Error(fmt.Sprintf("%s\n", err.Error()))
+func readData(dir string) (belenios.Election, belenios.Result, []belenios.Ballot, []belenios.Trustee) {
+	elec, res, ballots, trustees, err := belenios.ReadFiles(dir)
+	if err != nil {
+		Error(fmt.Sprintf("%s\n", err.Error()))
+	}
+	return elec, res, ballots, trustees
+}
+
+// describe Election
+func describeElection(elec belenios.Election) {
+	fmt.Println("\n= ", elec.Name, " =")
+	fmt.Println(elec.Description)
+	fmt.Println()
+	fmt.Printf("ID : %s\n", elec.UUID)
+	fmt.Printf("Admin : %s\n", elec.Administrator)
+	fmt.Printf("Credential Authority : %s\n", elec.CredentialAuthority)
+	fmt.Printf("Fingerprint : %s\n", belenios.Fingerprint(elec))
+	for _, f := range elec.Ignored {
+		color.Printf("<warning>Unknown field %s in election.json, not verified</>\n", f)
+	}
+	group := belenios.GroupName(elec)
+	if group == "" {
+		group = "custom group"
+	}
+	fmt.Printf("Group : %s\n\n", group)
+	fmt.Printf("Question(s) : %d\n", len(elec.Questions))
+	nh := 0
+	for _, q := range elec.Questions {
+		if q.Type == belenios.QuestionNonHomomorphic {
+			nh++
 		}
-		defer jsonFile.Close()
-
-		switch file {
-		case "election.json":
-			byteValue, _ := ioutil.ReadAll(jsonFile)
-			json.Unmarshal(byteValue, &elec)
-		case "result.json":
-			byteValue, _ := ioutil.ReadAll(jsonFile)
-			json.Unmarshal(byteValue, &res)
-		case "ballots.jsons": // one json ballot by line
-			scanner := bufio.NewScanner(jsonFile)
-			for scanner.Scan() {
-				var b Ballot
-				json.Unmarshal(scanner.Bytes(), &b)
-				ballots = append(ballots, b)
-			}
+	}
+	if nh > 0 {
+		fmt.Printf("Non-homomorphic question(s) : %d\n", nh)
+	}
+}
+
+// Print checks until first failure
+func printChecks(checks []belenios.Check, all bool) {
+	for _, c := range checks {
+		fmt.Printf("%s: ", c.Name)
+		switch {
+		case c.Err == nil:
+			color.Printf("<suc>OK</>\n")
+		case all:
+			color.Printf("<error>KO</>\n")
+		default:
+			Error(c.Err.Error())
 		}
 	}
+}
 
-	return elec, res, ballots
+// Summary of all failed checks
+func printFailures(failures []belenios.Failure) {
+	color.Printf("\n<error>Failures : %d</>\n\n", len(failures))
+	for _, f := range failures {
+		where := "Election"
+		if f.Ballot > 0 {
+			where = fmt.Sprintf("Ballot %d %s", f.Ballot, f.Tracker)
+		}
+		if f.Question > 0 {
+			where += fmt.Sprintf(" question %d", f.Question)
+		}
+		fmt.Printf("  %s: %s\n%s\n", where, f.Check, strings.TrimRight(f.Err.Error(), "\n"))
+	}
 }
 
-// describe Election
-// return : fingerprint, number of tests
-func describeElection(elec Election) (string, int) {
-	// [4.14] fingerprint of election
-	//  HJSON(J) = BASE64(SHA256(J))
-	J, _ := json.Marshal(elec)
-	hashJ := sha256.Sum256(J)
-	HJSON := base64.RawStdEncoding.EncodeToString(hashJ[:])
+// Print decrypted results
+func printResults(tables []belenios.ResultTable) {
+	for _, t := range tables {
+		fmt.Println("*", t.Question)
+		if t.Bounds != "" { // mask common case where min = max = 1
+			fmt.Printf("  (%s)\n", t.Bounds)
+		}
+		for _, row := range t.Rows {
+			fmt.Printf("  - %s : ", row.Answer)
+			color.Printf("<suc>%d</>\n", row.Count)
+		}
+		if t.Rows != nil {
+			continue
+		}
+		fmt.Printf("  (non-homomorphic, %d ballots)\n", len(t.Ballots))
+		for j, b := range t.Ballots {
+			fmt.Printf("  - Ballot %d : ", j+1)
+			color.Printf("<suc>%v</>\n", b)
+		}
+	}
+}
 
-	/**
-	Count tests for progression bar
-	**/
-	tests := 0
-	for _, q := range elec.Questions {
-		btest := len(q.Answers) //IndividualProofs
-		if q.Blank == true {    // BlankProof
-			btest += 2 // 1 blank + 1 individual
+// Print counting and intermediate tables of non-homomorphic questions
+func printCountings(elec belenios.Election, countings []belenios.Counting) {
+	for _, c := range countings {
+		q := elec.Questions[c.Question]
+		fmt.Println("*", q.Question)
+		switch {
+		case c.Schulze != nil:
+			s := c.Schulze
+			fmt.Printf("  Condorcet-Schulze, %d blank\n", s.Blank)
+			fmt.Printf("  Raw preferences:\n")
+			printMatrix(q, s.Raw)
+			fmt.Printf("  Strongest paths:\n")
+			printMatrix(q, s.Strength)
+			for k, group := range s.Winners {
+				fmt.Printf("  %d. ", k+1)
+				color.Printf("<suc>%s</>\n", answersNames(q, group))
+			}
+		case c.MJ != nil:
+			m := c.MJ
+			fmt.Printf("  Majority Judgment, %d blank, %d invalid\n", m.Blank, m.Invalid)
+			fmt.Printf("  Grades (1 is best):\n")
+			for a, grades := range m.Grades {
+				median := 0
+				if len(m.Medians[a]) > 0 {
+					median = m.Medians[a][0]
+				}
+				fmt.Printf("    %s : %v median %d\n", q.Answers[a], grades, median)
+			}
+			for k, group := range m.Winners {
+				fmt.Printf("  %d. ", k+1)
+				color.Printf("<suc>%s</>\n", answersNames(q, group))
+			}
+		case c.STV != nil:
+			s := c.STV
+			fmt.Printf("  STV, %d seats, quota %d, %d invalid\n", c.Seats, s.Quota, s.Invalid)
+			for k, r := range s.Rounds {
+				var votes []string
+				for a, v := range r.Votes {
+					if v >= 0 {
+						votes = append(votes, fmt.Sprintf("%s %d", q.Answers[a], v))
+					}
+				}
+				action := "eliminated"
+				if r.Elected {
+					action = "elected"
+				}
+				fmt.Printf("    Round %d: %s, %d exhausted -> %s %s\n", k+1, strings.Join(votes, ", "), r.Exhausted, q.Answers[r.Answer], action)
+			}
+			for k, a := range s.Winners {
+				fmt.Printf("  %d. ", k+1)
+				color.Printf("<suc>%s</>\n", q.Answers[a])
+			}
 		}
-		tests += btest
 	}
-	tests += 3 // Hash election + Signature + overall
+}
+
+func answersNames(q belenios.Question, group []int) string {
+	var names []string
+	for _, i := range group {
+		names = append(names, q.Answers[i])
+	}
+	return strings.Join(names, " = ")
+}
 
-	if Test == false {
-		fmt.Println("\n= ", elec.Name, " =")
-		fmt.Println(elec.Description)
+func printMatrix(q belenios.Question, m [][]int) {
+	for i, row := range m {
+		fmt.Printf("    %-10.10s", q.Answers[i])
+		for _, v := range row {
+			fmt.Printf(" %5d", v)
+		}
 		fmt.Println()
-		fmt.Printf("ID : %s\n", elec.UUID)
-		fmt.Printf("Admin : %s\n", elec.Administrator)
-		fmt.Printf("Credential Authority : %s\n", elec.CredentialAuthority)
-		fmt.Printf("Fingerprint : %s\n\n", HJSON)
-		fmt.Printf("Question(s) : %d\n", len(elec.Questions))
 	}
-	return HJSON, tests
+}
+
+// Write json audit report
+func writeReport(file string, a belenios.AuditReport) {
+	raw, err := json.MarshalIndent(a, "", "  ")
+	if err == nil {
+		err = ioutil.WriteFile(file, append(raw, '\n'), 0644)
+	}
+	if err != nil {
+		Error(fmt.Sprintf("Report %s: %s", file, err))
+	}
+}
+
+// Write html audit report
+func writeHTMLReport(file string, a belenios.AuditReport, elec belenios.Election, res belenios.Result) {
+	var buf bytes.Buffer
+	err := belenios.WriteHTMLReport(&buf, a, elec, res)
+	if err == nil {
+		err = ioutil.WriteFile(file, buf.Bytes(), 0644)
+	}
+	if err != nil {
+		Error(fmt.Sprintf("Report %s: %s", file, err))
+	}
 }
 
 // Download and verify ballot
@@ -146,8 +255,13 @@ func validateOnlineBallot(surl string, bhash string) error {
 		Error(fmt.Sprintf("GET %s (HTTP status: %d)", req.URL, resp.StatusCode))
 	}
 	byteValue, _ := ioutil.ReadAll(resp.Body)
-	var b Ballot
-	json.Unmarshal(byteValue, &b)
+	b, err := belenios.ReadBallot(bytes.TrimSpace(byteValue))
+	if err != nil {
+		Error(fmt.Sprintf("ballot: %s\n", err.Error()))
+	}
+	if b.Tracker != bhash {
+		Error(fmt.Sprintf(" Downloaded ballot with tracker %s\n", b.Tracker))
+	}
 
 	// Download election.json
 	uelec := url.URL{Scheme: u.Scheme, Host: u.Host, Path: path + "/election.json"}
@@ -161,38 +275,48 @@ func validateOnlineBallot(surl string, bhash string) error {
 		Error(fmt.Sprintf("GET %s (HTTP status: %d)", req.URL, resp.StatusCode))
 	}
 	byteValue, _ = ioutil.ReadAll(resp.Body)
-	var elec Election
-	json.Unmarshal(byteValue, &elec)
+	elec, err := belenios.ReadElection(byteValue)
+	if err != nil {
+		Error(fmt.Sprintf("election.json: %s\n", err.Error()))
+	}
 
 	// Print global description
-	HJSON, tests := describeElection(elec)
-	bar = progressbar.Default(int64(tests))
-
-	// Ballot verifications
-	fmt.Printf("\nBallot verifications:\n\n")
-	err = verifyResponseToElection(b, elec.UUID, HJSON)
+	describeElection(elec)
+	err = belenios.VerifyGroup(elec)
 	if err != nil {
 		Error(err.Error())
 	}
-	err = verifyBallotSignature(b, elec)
+
+	// Ballot verifications
+	fmt.Printf("\nBallot verifications: ")
+	v := belenios.Verifier{Election: elec}
+	err = v.VerifyBallot(b)
 	if err != nil {
 		Error(err.Error())
 	}
-	err = verifyBallotBlankProofs(b, elec)
-	if err != nil {
-		if err.Error() != "" { // for question without blank
-			Error(err.Error())
-		}
-	}
-	err = verifyBallotOverallProofs(b, elec)
+	return nil
+}
+
+// Find and verify ballot in local files
+func validateLocalBallot(dir string, bhash string) error {
+	elec, _, ballots, _ := readData(dir)
+	b, err := belenios.FindBallot(ballots, bhash)
 	if err != nil {
-		Error(err.Error())
+		return err
 	}
-	err = verifyBallotIndividualProofs(b, elec)
+	fmt.Printf("Found ballot: %s\n", bhash)
+
+	// Print global description
+	describeElection(elec)
+	err = belenios.VerifyGroup(elec)
 	if err != nil {
-		Error(err.Error())
+		return err
 	}
-	return nil
+
+	// Ballot verifications
+	fmt.Printf("\nBallot verifications: ")
+	v := belenios.Verifier{Election: elec}
+	return v.VerifyBallot(b)
 }
 
 /**
@@ -209,22 +333,46 @@ ______
 \____/ \___/|_|    \_/ \___/ 
 `
 	color.Info.Println(banner)
-	fmt.Println("A tool to verify Belenios homomorphic election")
+	fmt.Println("A tool to verify Belenios election")
 	fmt.Printf("%s\n\n", Version)
 
-	files := [4]string{"election.json", "result.json", "ballots.jsons", "trustees.json"}
-
 	/**
 	Manage flags
 	**/
-	fbhash := flag.String("b", "", "Online ballot hash (need url)")
+	fbhash := flag.String("b", "", "Ballot tracker (need url or dir)")
 	fdir := flag.String("dir", "", "Directory with files to audit")
 	furl := flag.String("url", "", "Election url to download files")
+	frevote := flag.Bool("revote", false, "Count last ballot of each credential (default: duplicate credentials are errors)")
+	fcount := flag.String("count", "", "Counting method for non-homomorphic questions: "+strings.Join(belenios.CountingMethods, ", "))
+	fseats := flag.Int("seats", 1, "Number of seats for stv counting")
+	farchive := flag.String("archive", "", "Election archive file to verify")
+	fall := flag.Bool("all", false, "Verify all ballots and checks, print failures and exit with status 1")
+	freport := flag.String("report", "", "Write a json audit report to file")
+	fhtml := flag.String("html", "", "Write a html audit report to file")
+	fjobs := flag.Int("j", 1, "Number of workers verifying ballots")
 	flag.Parse()
 
 	bhash := *fbhash
 	dir := *fdir
 	url := *furl
+	revote := *frevote
+	method := *fcount
+	seats := *fseats
+	archive := *farchive
+	all := *fall
+	report := *freport
+	htmlReport := *fhtml
+	jobs := *fjobs
+
+	if method != "" {
+		known := false
+		for _, m := range belenios.CountingMethods {
+			known = known || m == method
+		}
+		if !known || seats < 1 {
+			Error(fmt.Sprintf("Unknown counting method %s with %d seats", method, seats))
+		}
+	}
 
 	var re = regexp.MustCompile(`[/ ]$`) // clean last "/"
 	url = re.ReplaceAllString(url, "")
@@ -239,16 +387,34 @@ ______
 		os.Exit(0)
 	}
 
+	// Find and verify ballot in local directory
+	if bhash != "" && dir != "" {
+		err := validateLocalBallot(dir, bhash)
+		if err != nil {
+			Error(err.Error())
+		}
+		color.Printf("<suc>OK</>\n\n")
+		os.Exit(0)
+	}
+
+	if archive != "" && url != "" {
+		Error("Archive can not be downloaded, use -archive without -url")
+	}
+
 	// Test directory to store files
-	if dir == "" { // useless paranoiac test (managed by flag)
+	if dir == "" && archive == "" { // useless paranoiac test (managed by flag)
 		flag.PrintDefaults()
 		fmt.Println()
 		os.Exit(0)
 	}
 
-	isEmpty, err := IsEmptyDir(dir)
-	if err != nil {
-		Error(err.Error())
+	isEmpty := false
+	if dir != "" {
+		empty, err := IsEmptyDir(dir)
+		if err != nil {
+			Error(err.Error())
+		}
+		isEmpty = empty
 	}
 
 	/**
@@ -260,7 +426,7 @@ ______
 		}
 
 		fmt.Println("Download")
-		for _, fname := range files {
+		for _, fname := range belenios.Files {
 			req, _ := http.NewRequest("GET", url+"/"+fname, nil)
 			resp, err := http.DefaultClient.Do(req)
 			if err != nil {
@@ -280,6 +446,24 @@ ______
 			)
 			io.Copy(io.MultiWriter(f, barf), resp.Body)
 		}
+		// optional public credentials
+		for _, fname := range belenios.CredentialFiles {
+			resp, err := http.Get(url + "/" + fname)
+			if err != nil || resp.StatusCode != http.StatusOK {
+				continue
+			}
+			defer resp.Body.Close()
+
+			f, _ := os.OpenFile(dir+"/"+fname, os.O_CREATE|os.O_WRONLY, 0644)
+			defer f.Close()
+
+			barf := progressbar.DefaultBytes(
+				resp.ContentLength,
+				fmt.Sprintf("downloading %s", fname),
+			)
+			io.Copy(io.MultiWriter(f, barf), resp.Body)
+			break
+		}
 		fmt.Printf("\n\n")
 	}
 
@@ -287,61 +471,147 @@ ______
 	Read files
 	**/
 
-	elec, res, ballots := readData(files, dir)
+	var (
+		elec     belenios.Election
+		res      belenios.Result
+		ballots  []belenios.Ballot
+		trustees []belenios.Trustee
+		credFile string
+		creds    []string
+		weights  map[string]int
+		err      error
+	)
+	if archive != "" {
+		a, err := belenios.OpenArchive(archive)
+		if err != nil {
+			Error(err.Error())
+		}
+		elec, res, ballots, trustees = a.Election, a.Result, a.Ballots, a.Trustees
+		credFile, creds, weights = path.Base(archive), a.Credentials, a.Weights
+		fmt.Printf("Archive : %d events, last %s\n", a.Events, a.LastEvent)
+		if a.Shuffles > 0 {
+			color.Printf("<warning>Mixnet shuffles : %d, not verified</>\n", a.Shuffles)
+		}
+	} else {
+		elec, res, ballots, trustees = readData(dir)
+		credFile, creds, weights, err = belenios.ReadCredentials(dir)
+		if err != nil {
+			Error(err.Error())
+		}
+	}
 
 	/**
 	Process election
 	**/
 
 	// Print global description
-	HJSON, tests := describeElection(elec)
+	describeElection(elec)
 
+	fmt.Printf("Trustees : %d\n", len(trustees))
+	if credFile != "" {
+		fmt.Printf("Credentials : %d (%s)\n", len(creds), credFile)
+	}
 	color.Printf("Ballots : <suc>%d</>\n", len(ballots))
+	if len(weights) > 0 {
+		color.Printf("Ballots total weight : <suc>%d</>\n", belenios.TotalWeight(ballots, weights))
+	}
 
-	bar = progressbar.Default(int64(tests * len(ballots)))
-
-	// Ballots verifications
-	fmt.Printf("\nBallots verifications:\n\n")
-	for _, b := range ballots {
-		err := verifyResponseToElection(b, elec.UUID, HJSON)
-		if err != nil {
-			Error(err.Error())
+	v := belenios.Verifier{
+		Election: elec,
+		Trustees: trustees,
+		Weights:  weights,
+		Revote:   revote,
+		All:      all,
+		Workers:  jobs,
+	}
+	if credFile != "" {
+		v.Credentials = creds
+	}
+	fmt.Printf("\nVerifications:\n\n")
+	bar = progressbar.Default(int64(len(ballots)))
+	v.Progress = func() { bar.Add(1) }
+	r := v.Verify(ballots, res)
+	if report != "" || htmlReport != "" {
+		a := belenios.NewAuditReport(Version, elec, res, r, credFile != "")
+		if report != "" {
+			writeReport(report, a)
 		}
-		err = verifyBallotSignature(b, elec)
-		if err != nil {
-			Error(err.Error())
+		if htmlReport != "" {
+			writeHTMLReport(htmlReport, a, elec, res)
 		}
-		err = verifyBallotBlankProofs(b, elec)
-		if err != nil {
-			if err.Error() != "" { // for question without blank
-				Error(err.Error())
+	}
+
+	// Setup verifications
+	fmt.Printf("\nSetup verifications:\n\n")
+	printChecks(r.Setup, all)
+
+	// Ballots verifications
+	fmt.Printf("\nBallots verifications: ")
+	failed := 0
+	for _, b := range r.Ballots {
+		if err := b.Err(); err != nil {
+			if !all {
+				Error(fmt.Sprintf(" Ballot %s\n%s", b.Tracker, err.Error()))
 			}
+			failed++
 		}
-		err = verifyBallotOverallProofs(b, elec)
-		if err != nil {
-			Error(err.Error())
+	}
+	switch {
+	case len(r.Ballots) < len(ballots): // stopped at setup
+		color.Printf("<error>%d not verified</>\n", len(ballots)-len(r.Ballots))
+	case failed > 0:
+		color.Printf("<error>%d KO</>\n", failed)
+	default:
+		color.Printf("<suc>OK</>\n")
+	}
+
+	if credFile != "" {
+		fmt.Printf("\nCredentials : %d used, %d unused\n", r.CredentialsUsed, r.CredentialsUnused)
+	} else {
+		color.Printf("\n<warning>No public credentials file, ballots credentials not verified</>\n")
+	}
+
+	// Revote: last ballot counts
+	if len(r.Duplicates) > 0 {
+		fmt.Printf("\nDuplicate credentials:\n\n")
+		for _, d := range r.Duplicates {
+			fmt.Printf("  %d\t%s replaced by %s\n", d.Index, d.Tracker, d.ReplacedBy)
 		}
-		err = verifyBallotIndividualProofs(b, elec)
-		if err != nil {
-			Error(err.Error())
+		color.Printf("Ballots counted : <suc>%d</>\n", r.Counted)
+	}
+
+	fmt.Printf("\nBallots trackers:\n\n")
+	for _, b := range r.Ballots {
+		if b.Err() != nil {
+			color.Printf("  %d\t%s <error>KO</>\n", b.Index, b.Tracker)
+		} else {
+			color.Printf("  %d\t%s <suc>OK</>\n", b.Index, b.Tracker)
 		}
+	}
 
+	fmt.Printf("\nTally verifications:\n\n")
+	printChecks(r.Tally, all)
+	if r.TotalWeight > 0 {
+		fmt.Printf("  (%d ballots, total weight %d)\n", r.Counted, r.TotalWeight)
 	}
+	fmt.Println()
 
-	// Count, Decrypt, Print
-	fmt.Printf("\nBallots homomorphic count ...\n")
-	count := Count(elec, ballots)
-	err, results := DecryptResults(elec, res, count)
-	if err == nil {
-		fmt.Printf("\nDecrypted Results: ")
-		e := verifyDecryptedResults(results, res.Result)
-		if e != nil {
-			Error(e.Error())
+	printResults(belenios.ResultTables(elec, res, r.Results))
+	if method != "" && belenios.HasNonHomomorphic(elec) {
+		fmt.Printf("\nCounting %s:\n\n", method)
+		countings, err := belenios.CountNonHomomorphic(method, seats, elec, res)
+		if err != nil {
+			Error(err.Error())
 		}
-		color.Printf("<suc>OK</>\n\n")
-		PrintNewResults(elec, results)
+		printCountings(elec, countings)
 	}
 
 	fmt.Println()
 
+	if failures := r.Failures(); len(failures) > 0 {
+		printFailures(failures)
+		fmt.Println()
+		os.Exit(1)
+	}
+
 }